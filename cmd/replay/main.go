@@ -0,0 +1,34 @@
+// Replays a recording without window (for machines without display, like CI),
+// only the game package is imported so that no window system is needed
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gopherLand/game"
+	"log"
+)
+
+func main() {
+	config := game.DefaultConfig()
+	flag.StringVar(&config.LevelsPath, "levels", config.LevelsPath, "Path of the levels manifest")
+	flag.StringVar(&config.AtlasPath, "atlas", config.AtlasPath, "Path of the atlas manifest of the sprite sheet")
+	replayPath := flag.String("replay", "", "Replay the input recorded in this file")
+	flag.Parse()
+
+	if *replayPath == "" {
+		log.Fatal("needs a recording to replay (-replay)")
+	}
+	replay, err := game.LoadRecording(*replayPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g, err := replay.Replay(config)
+	fmt.Printf("Replayed %d frames: position (%.4f, %.4f), %d golds, %d keys\n", replay.Len(),
+		g.Player.Position.X, g.Player.Position.Y, g.Player.Gold, g.CountItem(game.KeyItem))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Replay matches the recording")
+}
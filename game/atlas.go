@@ -0,0 +1,88 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png" // Sprite sheets are PNG files
+	"os"
+	"sort"
+	"strings"
+)
+
+const DefaultAtlasPath string = "data/images/resources/atlas.json"
+
+// Rectangle of a sprite in the sprite sheet (pixels), of any size
+type Frame struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Sprite sheet with its frames named, as written in the atlas manifest
+type Atlas struct {
+	Image    string           `json:"image"`    // Path of the sprite sheet
+	CellSize int              `json:"cellSize"` // Pixels of the sheet for a block of the map
+	Frames   map[string]Frame `json:"frames"`   // Frames by name
+}
+
+// Returned when the atlas manifest is invalid, lists all problems
+type AtlasValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *AtlasValidationError) Error() string {
+	return fmt.Sprintf("invalid atlas in %s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Loads the atlas manifest, checking that its frames are inside the sprite sheet
+func LoadAtlas(path string) (Atlas, error) {
+	var atlas Atlas
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return atlas, err
+	}
+	if err := json.Unmarshal(content, &atlas); err != nil {
+		return atlas, fmt.Errorf("cannot read atlas in %s: %w", path, err)
+	}
+
+	sheet, err := os.Open(atlas.Image)
+	if err != nil {
+		return atlas, err
+	}
+	defer sheet.Close()
+	size, _, err := image.DecodeConfig(sheet)
+	if err != nil {
+		return atlas, fmt.Errorf("cannot read sprite sheet %s: %w", atlas.Image, err)
+	}
+
+	if problems := atlas.validate(size.Width, size.Height); len(problems) > 0 {
+		return atlas, &AtlasValidationError{path, problems}
+	}
+	return atlas, nil
+}
+
+// Checks the frames against the size of the sprite sheet, returns every problem found
+func (a Atlas) validate(width, height int) (problems []string) {
+	if a.CellSize <= 0 {
+		problems = append(problems, "cellSize must be positive")
+	}
+
+	// Sorted to always report problems in the same order
+	names := []string{}
+	for name := range a.Frames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := a.Frames[name]
+		if f.W <= 0 || f.H <= 0 {
+			problems = append(problems, fmt.Sprintf("frame %q needs a positive w and h", name))
+		} else if f.X < 0 || f.Y < 0 || f.X+f.W > width || f.Y+f.H > height {
+			problems = append(problems, fmt.Sprintf("frame %q is out of the sprite sheet (%dx%d)", name, width, height))
+		}
+	}
+	return
+}
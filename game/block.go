@@ -1,11 +1,33 @@
 package game
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+const DefaultBlocksPath string = "data/blocks.json"
+
 type Block struct {
-	Name        string          // Name of block
-	Short       rune            // Short identifier for blocks (to build maps)
-	Solidity    Solidity        // Solid can be walked over
-	Collectable bool            // Object can be collected
-	Images      []ImagePosition // List of images for displaying
+	Name          string    // Name of block
+	Short         rune      // Short identifier for blocks (to build maps)
+	Solidity      Solidity  // Solid can be walked over
+	Collectable   bool      // Object can be collected
+	Frames        []string  // Names of the atlas frames of the animation
+	TicksPerFrame int       // Number of ticks each image of the animation lasts
+	Entity        Behaviour // If set, the block is an entity moving around with this behaviour
+	Damage        int       // Hearts lost when touching the block
+	Trigger       Trigger   // If set, something happens when the player touches the block
+	Item          ItemID    // If set, collecting the block adds this item to the inventory
+	Loot          string    // If set, the block is a chest giving an entry of this loot table
+	Opened        rune      // Block replacing the chest once opened
+	Shop          string    // If set, the block is a shop selling these offers
+	Health        int       // Hits an entity takes before dying
+	Breakable     bool      // Block can be mined by the player
+	Hardness      float64   // Time to mine the block (seconds)
+	Drop          ItemID    // If set, mining the block adds this item to the inventory
 }
 
 // Solidity enum
@@ -17,63 +39,306 @@ const (
 	NotSolid Solidity = "NotSolid"
 )
 
-// Loads all blocks
-func (game *Game) loadResources() {
-	// Solid blocks
-	game.loadRessource("stone", 's', Solid, false, []ImagePosition{{0, 1, 0, 1}})
-	game.loadRessource("dirt", 'd', Solid, false, []ImagePosition{{1, 2, 0, 1}})
-	game.loadRessource("grass", 'g', Solid, false, []ImagePosition{{2, 3, 0, 1}})
-	game.loadRessource("brick", 'b', Solid, false, []ImagePosition{{3, 4, 0, 1}})
-	game.loadRessource("door_closed", 'C', Solid, false, []ImagePosition{{4, 5, 0, 1}})
-
-	// Not solid blocks
-	game.loadRessource("pillar_up_down", '0', NotSolid, false, []ImagePosition{{0, 1, 4, 5}})
-	game.loadRessource("pillar_down", '1', NotSolid, false, []ImagePosition{{1, 2, 4, 5}})
-	game.loadRessource("pillar_up", '2', NotSolid, false, []ImagePosition{{2, 3, 4, 5}})
-	game.loadRessource("pillar_central", '3', NotSolid, false, []ImagePosition{{3, 4, 4, 5}})
-	game.loadRessource("tree", 't', NotSolid, false, []ImagePosition{{4, 5, 1, 2}, {5, 6, 1, 2},
-		{6, 7, 1, 2}, {7, 8, 1, 2}})
-	game.loadRessource("herb", 'h', NotSolid, false, []ImagePosition{{0, 1, 1, 2}, {1, 2, 1, 2},
-		{2, 3, 1, 2}, {3, 4, 1, 2}})
-	game.loadRessource("door_opened", 'O', NotSolid, false, []ImagePosition{{5, 6, 0, 1}})
-
-	// Platforms
-	game.loadRessource("platform_none", '_', Platform, false, []ImagePosition{{6, 7, 0, 1}})
-	game.loadRessource("platform_left", '/', Platform, false, []ImagePosition{{7, 8, 0, 1}})
-	game.loadRessource("platform_right", '\\', Platform, false, []ImagePosition{{8, 9, 0, 1}})
-	game.loadRessource("platform_all", '-', Platform, false, []ImagePosition{{9, 10, 0, 1}})
-
-	// Collectable items
-	game.loadRessource("coin", 'c', NotSolid, true, []ImagePosition{{0, 1, 2, 3}, {1, 2, 2, 3},
-		{2, 3, 2, 3}, {3, 4, 2, 3}, {4, 5, 2, 3}, {5, 6, 2, 3}})
-	game.loadRessource("key", 'k', NotSolid, true, []ImagePosition{{6, 7, 2, 3}, {7, 8, 2, 3}})
-
-	// Player
-	game.loadRessource("player", 'p', NotSolid, false, []ImagePosition{{0, 1, 3, 4}, {1, 2, 3, 4},
-		{2, 3, 3, 4}, {3, 4, 3, 4}, {4, 5, 3, 4}, {5, 6, 3, 4}, {6, 7, 3, 4}, {7, 8, 3, 4}})
-
-	// Air
-	game.loadRessource("air", ' ', NotSolid, false, []ImagePosition{})
+// Content of the blocks definitions file
+type blocksFile struct {
+	Blocks []blockDefinition      `json:"blocks"`
+	Items  []itemDefinition       `json:"items"`
+	Loot   map[string][]LootEntry `json:"loot"`  // Loot tables of chests, by name
+	Shops  map[string][]ShopOffer `json:"shops"` // Offers of shops, by name
+}
+
+// Definition of a single block in the blocks definitions file
+type blockDefinition struct {
+	Name          string    `json:"name"`
+	Short         string    `json:"short"`
+	Solidity      Solidity  `json:"solidity"`
+	Collectable   bool      `json:"collectable"`
+	Frames        []string  `json:"frames"`        // Animation frames, by name in the atlas
+	TicksPerFrame int       `json:"ticksPerFrame"` // Defaults to 1
+	Entity        Behaviour `json:"entity"`
+	Damage        int       `json:"damage"`
+	Trigger       Trigger   `json:"trigger"`
+	Item          ItemID    `json:"item"`
+	Loot          string    `json:"loot"`
+	Opened        string    `json:"opened"` // Short of the block replacing the chest once opened
+	Shop          string    `json:"shop"`
+	Health        int       `json:"health"` // Defaults to 1 for entities
+	Breakable     bool      `json:"breakable"`
+	Hardness      float64   `json:"hardness"`
+	Drop          ItemID    `json:"drop"`
+}
+
+// Definition of an item type in the blocks definitions file
+type itemDefinition struct {
+	ID          ItemID    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Stack       int       `json:"stack"` // Most items in a slot, defaults to 1
+	Use         UseAction `json:"use"`
+	Icon        string    `json:"icon"` // Frame of the atlas
+	Weapon      Weapon    `json:"weapon"`
+	Block       string    `json:"block"` // Short of the block placed with the item
 }
 
-// Loads a single block
-func (game *Game) loadRessource(name string, short rune, solid Solidity, collectable bool, images []ImagePosition) {
-	imagePos := []ImagePosition{}
-
-	for _, v := range images {
-		imagePos = append(imagePos, ImagePosition{
-			game.BlockSize * v.X1,
-			game.BlockSize * v.X2,
-			game.BlockSize * v.Y1,
-			game.BlockSize * v.Y2,
-		})
+// Returned when the blocks definitions file is invalid, lists all problems
+type BlocksValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *BlocksValidationError) Error() string {
+	return fmt.Sprintf("invalid blocks definitions in %s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Loads all blocks from the blocks definitions file
+func (game *Game) loadResources(blocksPath string) error {
+	content, err := os.ReadFile(blocksPath)
+	if err != nil {
+		return err
+	}
+	game.blocksHash = hashContent(content)
+
+	var file blocksFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return fmt.Errorf("cannot read blocks definitions in %s: %w", blocksPath, err)
+	}
+
+	if problems := file.validate(game.Atlas); len(problems) > 0 {
+		return &BlocksValidationError{blocksPath, problems}
+	}
+
+	for _, b := range file.Blocks {
+		short, _ := utf8.DecodeRuneInString(b.Short)
+		opened, _ := utf8.DecodeRuneInString(b.Opened)
+		game.AllBlocks[short] = Block{
+			Name:          b.Name,
+			Short:         short,
+			Solidity:      b.Solidity,
+			Collectable:   b.Collectable,
+			Frames:        b.Frames,
+			TicksPerFrame: b.TicksPerFrame,
+			Entity:        b.Entity,
+			Damage:        b.Damage,
+			Trigger:       b.Trigger,
+			Item:          b.Item,
+			Loot:          b.Loot,
+			Opened:        opened,
+			Shop:          b.Shop,
+			Health:        b.Health,
+			Breakable:     b.Breakable,
+			Hardness:      b.Hardness,
+			Drop:          b.Drop,
+		}
+	}
+	for _, i := range file.Items {
+		block, _ := utf8.DecodeRuneInString(i.Block)
+		game.Items[i.ID] = ItemType{
+			ID:          i.ID,
+			Name:        i.Name,
+			Description: i.Description,
+			StackLimit:  i.Stack,
+			Use:         i.Use,
+			Icon:        i.Icon,
+			Weapon:      i.Weapon,
+			Block:       block,
+		}
+	}
+	game.LootTables = file.Loot
+	game.Shops = file.Shops
+	return nil
+}
+
+// Checks the definitions and fills default values, returns every problem found
+func (file *blocksFile) validate(atlas Atlas) (problems []string) {
+	items := map[ItemID]bool{}
+	for i := range file.Items {
+		it := &file.Items[i]
+
+		if it.ID == "" {
+			problems = append(problems, fmt.Sprintf("item #%d has no id", i+1))
+		} else if items[it.ID] {
+			problems = append(problems, fmt.Sprintf("duplicate item id %q", it.ID))
+		}
+		items[it.ID] = true
+
+		switch it.Use {
+		case "", OpenDoor, Heal, Equip, Place:
+		default:
+			problems = append(problems, fmt.Sprintf("item %q: unknown use %q", it.ID, it.Use))
+		}
+
+		switch it.Weapon.Kind {
+		case "":
+			if it.Use == Equip {
+				problems = append(problems, fmt.Sprintf("item %q: only weapons can be equipped", it.ID))
+			}
+		case Melee, Ranged:
+			if it.Weapon.Damage <= 0 || it.Weapon.Range <= 0 || it.Weapon.Cooldown < 0 ||
+				(it.Weapon.Kind == Ranged && it.Weapon.Speed <= 0) {
+				problems = append(problems, fmt.Sprintf("item %q: weapon needs a positive damage, range and speed", it.ID))
+			}
+			if it.Use != Equip {
+				problems = append(problems, fmt.Sprintf("item %q: weapons must be used with Equip", it.ID))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("item %q: unknown weapon kind %q", it.ID, it.Weapon.Kind))
+		}
+
+		if (it.Use == Place) != (it.Block != "") {
+			problems = append(problems, fmt.Sprintf("item %q: blocks must be used with Place", it.ID))
+		}
+
+		if it.Stack == 0 {
+			it.Stack = 1
+		} else if it.Stack < 0 {
+			problems = append(problems, fmt.Sprintf("item %q: stack must be positive", it.ID))
+		}
+
+		if _, ok := atlas.Frames[it.Icon]; !ok {
+			problems = append(problems, fmt.Sprintf("item %q: unknown icon frame %q", it.ID, it.Icon))
+		}
+	}
+
+	for name, table := range file.Loot {
+		for i := range table {
+			e := &table[i]
+			if e.Count == 0 {
+				e.Count = 1
+			}
+			if e.Weight == 0 {
+				e.Weight = 1
+			}
+			if e.Gold < 0 || e.Count < 0 || e.Weight < 0 {
+				problems = append(problems, fmt.Sprintf("loot %q: entry #%d must have positive gold, count and weight", name, i+1))
+			}
+			if e.Item != "" && !items[e.Item] {
+				problems = append(problems, fmt.Sprintf("loot %q: unknown item %q", name, e.Item))
+			}
+		}
 	}
 
-	game.AllBlocks[short] = Block{
-		name,
-		short,
-		solid,
-		collectable,
-		imagePos,
+	for name, offers := range file.Shops {
+		for i := range offers {
+			o := &offers[i]
+			if o.Count == 0 {
+				o.Count = 1
+			}
+			if o.Price < 0 || o.Count < 0 {
+				problems = append(problems, fmt.Sprintf("shop %q: offer #%d must have a positive price and count", name, i+1))
+			}
+			if !items[o.Item] {
+				problems = append(problems, fmt.Sprintf("shop %q: unknown item %q", name, o.Item))
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	shorts := map[string]string{}
+	for i := range file.Blocks {
+		b := &file.Blocks[i]
+
+		if b.Name == "" {
+			problems = append(problems, fmt.Sprintf("block #%d has no name", i+1))
+		} else if names[b.Name] {
+			problems = append(problems, fmt.Sprintf("duplicate block name %q", b.Name))
+		}
+		names[b.Name] = true
+
+		if utf8.RuneCountInString(b.Short) != 1 {
+			problems = append(problems, fmt.Sprintf("block %q: short must be a single character, got %q", b.Name, b.Short))
+		} else if other, ok := shorts[b.Short]; ok {
+			problems = append(problems, fmt.Sprintf("block %q: short %q is already used by %q", b.Name, b.Short, other))
+		} else {
+			shorts[b.Short] = b.Name
+		}
+
+		switch b.Solidity {
+		case Solid, Platform, NotSolid:
+		default:
+			problems = append(problems, fmt.Sprintf("block %q: unknown solidity %q", b.Name, b.Solidity))
+		}
+
+		switch b.Entity {
+		case "", Walker:
+		default:
+			problems = append(problems, fmt.Sprintf("block %q: unknown entity behaviour %q", b.Name, b.Entity))
+		}
+
+		switch b.Trigger {
+		case "", Start, Checkpoint, Exit:
+		default:
+			problems = append(problems, fmt.Sprintf("block %q: unknown trigger %q", b.Name, b.Trigger))
+		}
+
+		if b.Entity != "" && b.Health == 0 {
+			b.Health = 1
+		} else if b.Health < 0 {
+			problems = append(problems, fmt.Sprintf("block %q: health must be positive", b.Name))
+		}
+
+		if b.Damage < 0 {
+			problems = append(problems, fmt.Sprintf("block %q: damage must be positive", b.Name))
+		}
+
+		if b.Hardness < 0 {
+			problems = append(problems, fmt.Sprintf("block %q: hardness must be positive", b.Name))
+		}
+		if !b.Breakable && (b.Hardness != 0 || b.Drop != "") {
+			problems = append(problems, fmt.Sprintf("block %q: only breakable blocks have a hardness and a drop", b.Name))
+		}
+		if b.Drop != "" && !items[b.Drop] {
+			problems = append(problems, fmt.Sprintf("block %q: unknown drop %q", b.Name, b.Drop))
+		}
+
+		if b.Item != "" && !items[b.Item] {
+			problems = append(problems, fmt.Sprintf("block %q: unknown item %q", b.Name, b.Item))
+		}
+
+		if _, ok := file.Loot[b.Loot]; b.Loot != "" && !ok {
+			problems = append(problems, fmt.Sprintf("block %q: unknown loot table %q", b.Name, b.Loot))
+		}
+		if _, ok := file.Shops[b.Shop]; b.Shop != "" && !ok {
+			problems = append(problems, fmt.Sprintf("block %q: unknown shop %q", b.Name, b.Shop))
+		}
+		if b.Loot != "" && b.Opened == "" {
+			problems = append(problems, fmt.Sprintf("block %q: a chest needs an opened block", b.Name))
+		}
+
+		for _, f := range b.Frames {
+			if _, ok := atlas.Frames[f]; !ok {
+				problems = append(problems, fmt.Sprintf("block %q: unknown frame %q", b.Name, f))
+			}
+		}
+
+		if b.TicksPerFrame == 0 {
+			b.TicksPerFrame = 1
+		} else if b.TicksPerFrame < 0 {
+			problems = append(problems, fmt.Sprintf("block %q: ticksPerFrame must be positive", b.Name))
+		}
+	}
+
+	for _, b := range file.Blocks {
+		if _, ok := shorts[b.Opened]; b.Opened != "" && !ok {
+			problems = append(problems, fmt.Sprintf("block %q: unknown opened block %q", b.Name, b.Opened))
+		}
+	}
+	for _, it := range file.Items {
+		if _, ok := shorts[it.Block]; it.Block != "" && !ok {
+			problems = append(problems, fmt.Sprintf("item %q: unknown block %q", it.ID, it.Block))
+		}
+	}
+
+	if _, ok := shorts[" "]; !ok {
+		problems = append(problems, "missing the air block (short \" \")")
+	}
+	if _, ok := shorts["p"]; !ok {
+		problems = append(problems, "missing the player block (short \"p\")")
+	}
+	for _, b := range file.Blocks {
+		// Standing uses the second frame, walking animates all of them
+		if b.Short == "p" && len(b.Frames) < 2 {
+			problems = append(problems, fmt.Sprintf("block %q: the player needs at least 2 frames", b.Name))
+		}
 	}
+	return
 }
@@ -0,0 +1,87 @@
+package game
+
+import "math"
+
+// Block being mined by the player
+type Mining struct {
+	X    int
+	Y    int
+	Time float64 // Time spent mining the block (seconds), it breaks once its hardness is reached
+}
+
+// Mines the block in front of the player while the mine key is held,
+// and places the block held when the place key has just been pressed
+func (g *Game) stepBuild(input InputState) {
+	x, y := g.facingCell()
+
+	if input.Mine && !g.outOfMap([]int{x}, []int{y}) && g.AllBlocks[g.GameMap[x][y]].Breakable {
+		if g.Mining.X != x || g.Mining.Y != y {
+			g.Mining = Mining{x, y, 0}
+		}
+		g.Mining.Time += FixedDelta
+		if g.Mining.Time >= g.AllBlocks[g.GameMap[x][y]].Hardness {
+			g.mineBlock(x, y)
+			g.Mining = Mining{}
+		}
+	} else {
+		g.Mining = Mining{}
+	}
+
+	pressed := input.Place && !g.placeHeld
+	g.placeHeld = input.Place
+	if pressed {
+		g.placeBlock(x, y)
+	}
+}
+
+// Returns the cell next to the player, on the side the player faces
+func (g *Game) facingCell() (x, y int) {
+	x = int(math.Floor(g.Player.Position.X)) + 1
+	if g.Player.Direction == 'l' {
+		x = int(math.Floor(g.Player.Position.X)) - 1
+	}
+	return x, int(math.Floor(g.Player.Position.Y))
+}
+
+// Replaces a block by air, giving its drop to the player
+// (the block stays if the inventory cannot take the drop)
+func (g *Game) mineBlock(x, y int) {
+	b := g.AllBlocks[g.GameMap[x][y]]
+	if b.Drop != "" && g.AddItem(b.Drop, 1) == 0 {
+		cell := Position{float64(x) + 0.5, float64(y) + 0.5}
+		g.events = append(g.events, Event{Kind: InventoryFull, Position: cell, Loot: LootEntry{0, b.Drop, 1, 1}})
+		return
+	}
+	g.GameMap[x][y] = ' '
+}
+
+// Puts the block held by the player in an empty cell, using one item,
+// returns true if it was placed
+func (g *Game) placeBlock(x, y int) bool {
+	if g.outOfMap([]int{x}, []int{y}) || g.GameMap[x][y] != ' ' || g.CountItem(g.Player.Block) == 0 {
+		return false
+	}
+
+	// A block cannot be placed over the player or an entity
+	block := g.AllBlocks[g.Items[g.Player.Block].Block]
+	cell := AABB{float64(x), float64(y), float64(x + 1), float64(y + 1)}
+	if block.Solidity != NotSolid {
+		if overlaps(cell, g.Player.Box()) {
+			return false
+		}
+		for _, e := range g.Entities {
+			if overlaps(cell, e.Box()) {
+				return false
+			}
+		}
+	}
+
+	for i, s := range g.Player.Inventory {
+		if s.Item == g.Player.Block {
+			g.removeItem(i)
+			break
+		}
+	}
+	g.GameMap[x][y] = block.Short
+	return true
+}
@@ -0,0 +1,88 @@
+package game
+
+// Possible content of a chest, in a loot table of the blocks definitions file
+type LootEntry struct {
+	Gold   int    `json:"gold"`   // Golds given
+	Item   ItemID `json:"item"`   // Item given, if set
+	Count  int    `json:"count"`  // Number of items given, defaults to 1
+	Weight int    `json:"weight"` // Chances to be drawn compared to the other entries, defaults to 1
+}
+
+// Opens the chest the player stands on or is next to (on the side the player faces first),
+// or the shop, when the interact key has just been pressed
+func (g *Game) stepInteract(input InputState) {
+	pressed := input.Interact && !g.interactHeld
+	g.interactHeld = input.Interact
+	if !pressed {
+		return
+	}
+
+	x := int(g.Player.Position.X)
+	y := int(g.Player.Position.Y)
+	sides := []int{x, x + 1, x - 1}
+	if g.Player.Direction == 'l' {
+		sides = []int{x, x - 1, x + 1}
+	}
+	for _, side := range sides {
+		if !g.outOfMap([]int{side}, []int{y}) && g.AllBlocks[g.GameMap[side][y]].Loot != "" {
+			g.openChest(side, y)
+			return
+		}
+	}
+	if shop, x, y := g.nearShop(); shop != "" {
+		cell := Position{float64(x) + 0.5, float64(y) + 0.5}
+		g.events = append(g.events, Event{Kind: ShopOpened, Position: cell, Shop: shop})
+	}
+}
+
+// Gives the player an entry drawn from the loot table of a chest, and opens it
+// (the chest stays closed if the inventory cannot take all the items,
+// and gives the same entry at the next try)
+func (g *Game) openChest(x, y int) {
+	b := g.AllBlocks[g.GameMap[x][y]]
+	cell := Position{float64(x) + 0.5, float64(y) + 0.5}
+	seed := g.seed
+	loot := g.drawLoot(g.LootTables[b.Loot])
+	if loot.Item != "" && g.roomFor(loot.Item) < loot.Count {
+		g.seed = seed // Drawn again only once the chest opens, it cannot be re-rolled
+		g.events = append(g.events, Event{Kind: InventoryFull, Position: cell, Loot: loot})
+		return
+	}
+	if loot.Item != "" {
+		g.AddItem(loot.Item, loot.Count)
+	}
+	g.Player.CollectGold(loot.Gold)
+	g.GameMap[x][y] = b.Opened
+	g.events = append(g.events, Event{Kind: ChestOpened, Position: cell, Loot: loot})
+}
+
+// Draws an entry of a loot table depending on weights
+func (g *Game) drawLoot(table []LootEntry) LootEntry {
+	total := 0
+	for _, e := range table {
+		total += e.Weight
+	}
+	if total <= 0 {
+		return LootEntry{}
+	}
+	n := int(g.random() % uint64(total))
+	for _, e := range table {
+		if n < e.Weight {
+			return e
+		}
+		n -= e.Weight
+	}
+	return LootEntry{}
+}
+
+// Returns the next number of the game's random generator (xorshift),
+// saved with the game so that replays and loads draw the same loot
+func (g *Game) random() uint64 {
+	if g.seed == 0 {
+		g.seed = 1
+	}
+	g.seed ^= g.seed << 13
+	g.seed ^= g.seed >> 7
+	g.seed ^= g.seed << 17
+	return g.seed
+}
@@ -0,0 +1,76 @@
+package game
+
+import "testing"
+
+// Map with a chest next to the player
+const chestTestMap string = `[collision]
+s    s
+s  $ s
+ssssss
+[entities]
+
+  p
+`
+
+// Fills the inventory with one item per slot
+func (g *Game) fillInventory(id ItemID) {
+	for len(g.Player.Inventory) < inventorySize {
+		g.Player.Inventory = append(g.Player.Inventory, ItemStack{id, g.Items[id].StackLimit})
+	}
+}
+
+// Presses and releases the interact key
+func (g *Game) interact() {
+	g.Step(InputState{Interact: true})
+	g.Step(InputState{})
+}
+
+func TestChestFullInventoryKeepsTheDraw(t *testing.T) {
+	g := newTestGame(t, chestTestMap)
+	g.LootTables["chest"] = []LootEntry{{0, "potion", 1, 1}, {50, "", 1, 1}}
+	g.fillInventory("sword")
+
+	// Seed drawing the potion first
+	for g.seed = 1; ; g.seed++ {
+		seed := g.seed
+		if g.drawLoot(g.LootTables["chest"]).Item == "potion" {
+			g.seed = seed
+			break
+		}
+	}
+
+	// The chest cannot be opened again and again until it gives gold
+	for i := 0; i < 20; i++ {
+		g.interact()
+		if g.GameMap[3][1] != '$' || g.Player.Gold != 0 {
+			t.Fatalf("try %d: chest opened with a full inventory (gold %d)", i+1, g.Player.Gold)
+		}
+	}
+
+	g.Player.Inventory = nil
+	g.interact()
+	if g.GameMap[3][1] != '&' || g.CountItem("potion") != 1 || g.Player.Gold != 0 {
+		t.Errorf("chest gave %d potions and %d golds, expected the potion drawn first", g.CountItem("potion"), g.Player.Gold)
+	}
+}
+
+func TestChestPartialFitStaysClosed(t *testing.T) {
+	g := newTestGame(t, chestTestMap)
+	g.LootTables["chest"] = []LootEntry{{0, "key", 3, 1}}
+	g.fillInventory("sword")
+	g.Player.Inventory[0] = ItemStack{"key", g.Items["key"].StackLimit - 2}
+
+	g.interact()
+	if g.GameMap[3][1] != '$' {
+		t.Error("chest opened while only part of its loot fits")
+	}
+	if got := g.CountItem("key"); got != g.Items["key"].StackLimit-2 {
+		t.Errorf("got %d keys, expected none to be added", got)
+	}
+
+	g.Player.Inventory[0].Count = 1
+	g.interact()
+	if g.GameMap[3][1] != '&' || g.CountItem("key") != 4 {
+		t.Errorf("chest not opened (got %d keys) once the loot fits", g.CountItem("key"))
+	}
+}
@@ -0,0 +1,117 @@
+package game
+
+import "math"
+
+const skin float64 = 1e-4 // Gap kept between the player and the blocks it touches (blocks)
+
+// Block standing for the left and right borders of the map
+var borderBlock = Block{Name: "border", Short: 0, Solidity: Solid}
+
+// Axis-aligned box, in blocks
+type AABB struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+// Contact found when sweeping a box
+type Hit struct {
+	Time    float64 // Fraction of the move done before the contact (0 to 1)
+	NormalX float64 // Normal of the hit face of the block (-1, 0 or 1)
+	NormalY float64
+	X       int // Cell of the block hit
+	Y       int
+	Block   Block // Block hit
+}
+
+// Returns the box moved by (x, y)
+func (b AABB) Translate(x, y float64) AABB {
+	return AABB{b.MinX + x, b.MinY + y, b.MaxX + x, b.MaxY + y}
+}
+
+// Sweeps the box along the move (dx, dy) and returns the first block it hits, if any.
+// Blocks the box already overlaps are ignored so that it can get out of them.
+// Platforms only stop a box falling onto their top edge, and only if platforms is true.
+func (g *Game) Sweep(box AABB, dx, dy float64, platforms bool) (hit Hit, ok bool) {
+	hit.Time = math.Inf(1)
+
+	// Cells covered by the whole move
+	xFrom := int(math.Floor(math.Min(box.MinX, box.MinX+dx)))
+	xTo := int(math.Floor(math.Max(box.MaxX, box.MaxX+dx)))
+	yFrom := int(math.Floor(math.Min(box.MinY, box.MinY+dy)))
+	yTo := int(math.Floor(math.Max(box.MaxY, box.MaxY+dy)))
+
+	for x := xFrom; x <= xTo; x++ {
+		for y := yFrom; y <= yTo; y++ {
+			block, solid := g.collisionBlock(x, y)
+			if !solid {
+				continue
+			}
+			if block.Solidity == Platform && (!platforms || dy <= 0 || box.MaxY > float64(y)+skin) {
+				continue // One-way platform, crossed from below or from the sides
+			}
+
+			cell := AABB{float64(x), float64(y), float64(x + 1), float64(y + 1)}
+			t, nx, ny, found := sweepAABB(box, cell, dx, dy)
+			if found && t < hit.Time {
+				hit = Hit{t, nx, ny, x, y, block}
+				ok = true
+			}
+		}
+	}
+	return
+}
+
+// Returns the block of a cell and if it stops the player
+// (the left and right borders of the map are walls, above and below is air)
+func (g *Game) collisionBlock(x, y int) (Block, bool) {
+	if x < 0 || x >= g.width {
+		return borderBlock, true
+	}
+	if y < 0 || y >= g.height {
+		return g.AllBlocks[' '], false
+	}
+	b := g.AllBlocks[g.GameMap[x][y]]
+	return b, b.Solidity != NotSolid
+}
+
+// Computes when a moving box enters a static one, as a fraction of the move,
+// with the normal of the face hit
+func sweepAABB(box, static AABB, dx, dy float64) (t, nx, ny float64, found bool) {
+	xEntry, xExit, ok := axisEntryExit(box.MinX, box.MaxX, static.MinX, static.MaxX, dx)
+	if !ok {
+		return
+	}
+	yEntry, yExit, ok := axisEntryExit(box.MinY, box.MaxY, static.MinY, static.MaxY, dy)
+	if !ok {
+		return
+	}
+
+	entry := math.Max(xEntry, yEntry)
+	exit := math.Min(xExit, yExit)
+	if entry >= exit || entry < 0 || entry > 1 {
+		return // No contact during this move, or already overlapping
+	}
+
+	if xEntry > yEntry {
+		nx = -math.Copysign(1, dx)
+	} else {
+		ny = -math.Copysign(1, dy)
+	}
+	return entry, nx, ny, true
+}
+
+// Computes the fractions of the move where a segment enters and leaves another one on an axis.
+// Returns false if they never overlap.
+func axisEntryExit(min, max, staticMin, staticMax, d float64) (entry, exit float64, ok bool) {
+	switch {
+	case d > 0:
+		return (staticMin - max) / d, (staticMax - min) / d, true
+	case d < 0:
+		return (staticMax - min) / d, (staticMin - max) / d, true
+	case max <= staticMin || min >= staticMax:
+		return 0, 0, false
+	}
+	return math.Inf(-1), math.Inf(1), true
+}
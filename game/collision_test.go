@@ -0,0 +1,70 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSweep(t *testing.T) {
+	g := newTestGame(t, `[collision]
+
+  s _
+ssssss
+[entities]
+ p
+`)
+	tests := []struct {
+		name      string
+		box       AABB
+		dx, dy    float64
+		platforms bool
+		hit       bool
+		time      float64 // Fraction of the move done before the hit
+		nx, ny    float64 // Normal of the face hit
+		x, y      int     // Cell hit
+	}{
+		{"air", AABB{0.2, 0.2, 0.8, 0.8}, 0.5, 0, true, false, 0, 0, 0, 0, 0},
+		{"wall on the right", AABB{1, 1, 1.5, 2}, 1, 0, true, true, 0.5, -1, 0, 2, 1},
+		{"wall on the left", AABB{3.5, 1, 4, 2}, -1, 0, true, true, 0.5, 1, 0, 2, 1},
+		{"ground", AABB{0.2, 1.5, 0.8, 1.9}, 0, 0.5, true, true, 0.2, 0, -1, 0, 2},
+		{"tunneling through a thin wall", AABB{0, 1, 0.5, 2}, 5, 0, true, true, 0.3, -1, 0, 2, 1},
+		{"left border", AABB{0.5, 0.2, 1, 0.8}, -1, 0, true, true, 0.5, 1, 0, -1, 0},
+		{"already overlapping", AABB{2.2, 1.2, 2.8, 1.8}, 0.5, 0, true, false, 0, 0, 0, 0, 0},
+		{"platform from above", AABB{4.2, 0.5, 4.8, 1}, 0, 0.5, true, true, 0, 0, -1, 4, 1},
+		{"platform without platforms", AABB{4.2, 0.5, 4.8, 1}, 0, 0.5, false, false, 0, 0, 0, 0, 0},
+		{"platform from below", AABB{4.2, 2, 4.8, 2.5}, 0, -0.5, true, false, 0, 0, 0, 0, 0},
+		{"platform from the side", AABB{3.2, 1.2, 3.8, 1.8}, 1, 0, true, false, 0, 0, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		hit, ok := g.Sweep(test.box, test.dx, test.dy, test.platforms)
+		if ok != test.hit {
+			t.Errorf("%s: hit is %v, expected %v", test.name, ok, test.hit)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if math.Abs(hit.Time-test.time) > 1e-9 || hit.NormalX != test.nx || hit.NormalY != test.ny ||
+			hit.X != test.x || hit.Y != test.y {
+			t.Errorf("%s: got %+v, expected time %.2f, normal (%.0f, %.0f) on cell (%d, %d)",
+				test.name, hit, test.time, test.nx, test.ny, test.x, test.y)
+		}
+	}
+}
+
+func TestFlushMove(t *testing.T) {
+	tests := []struct {
+		d, time, expected float64
+	}{
+		{1, 0.5, 0.5 - skin},
+		{-1, 0.5, -0.5 + skin},
+		{1, 0, 0},
+		{-1, 0, 0},
+	}
+	for _, test := range tests {
+		if got := flushMove(test.d, test.time); math.Abs(got-test.expected) > 1e-12 {
+			t.Errorf("flushMove(%v, %v) = %v, expected %v", test.d, test.time, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package game
+
+// Behaviour enum
+type Behaviour string
+
+const (
+	Walker Behaviour = "Walker" // Patrols between walls and ledges
+)
+
+const walkerSpeed float64 = 2.0 // Walking speed of walkers (blocks per second)
+const enemyDamage int = 1       // Hearts lost when touching an enemy
+
+type Entity struct {
+	Short     rune      // Block of the entity (images), as placed in the map
+	Behaviour Behaviour // How the entity moves
+	Position  Position
+	Hitbox    AABB    // Box around the position
+	VelocityX float64 // Horizontal velocity (blocks per second)
+	VelocityY float64 // Vertical velocity (blocks per second)
+	Direction rune    // l or r
+	Grounded  bool    // True if standing on a block
+
+	Health       int     // Hits left before dying
+	Invulnerable float64 // Time left without taking damage after a hit (seconds)
+}
+
+// Creates an entity of a block, standing in a cell of the map
+func newEntity(b Block, x, y int) Entity {
+	e := Entity{
+		Short:     b.Short,
+		Behaviour: b.Entity,
+		Position:  Position{float64(x) + 0.5, float64(y) + 0.5},
+		Hitbox:    AABB{-0.35, -0.2, 0.35, 0.5},
+		Direction: 'r',
+		Health:    b.Health,
+	}
+	switch e.Behaviour {
+	case Walker:
+		e.VelocityX = walkerSpeed
+	}
+	return e
+}
+
+// Returns the hitbox of the entity at its position
+func (e *Entity) Box() AABB {
+	return e.Hitbox.Translate(e.Position.X, e.Position.Y)
+}
+
+// Moves every entity by one physics step, and checks if they touch the player
+func (g *Game) stepEntities() {
+	for i := range g.Entities {
+		e := &g.Entities[i]
+		if e.Invulnerable > 0 {
+			e.Invulnerable -= FixedDelta
+		}
+		switch e.Behaviour {
+		case Walker:
+			g.stepWalker(e)
+		}
+
+		if overlaps(e.Box(), g.Player.Box()) {
+			g.HurtPlayer(enemyDamage)
+		}
+	}
+}
+
+// Walks forward, turning back in front of walls and ledges
+func (g *Game) stepWalker(e *Entity) {
+	// Falling
+	e.VelocityY += gravity * FixedDelta
+	dy := e.VelocityY * FixedDelta
+	if hit, ok := g.Sweep(e.Box(), 0, dy, true); ok {
+		dy = flushMove(dy, hit.Time)
+		e.VelocityY = 0
+		e.Grounded = hit.NormalY < 0
+	} else {
+		e.Grounded = false
+	}
+	e.Position.Y += dy
+
+	if !e.Grounded {
+		return
+	}
+
+	// Turning back before a ledge
+	if g.ledgeAhead(e) {
+		e.VelocityX = -e.VelocityX
+	}
+
+	// Walking, turning back against a wall
+	dx := e.VelocityX * FixedDelta
+	if hit, ok := g.Sweep(e.Box(), dx, 0, true); ok {
+		dx = flushMove(dx, hit.Time)
+		e.VelocityX = -e.VelocityX
+	}
+	e.Position.X += dx
+
+	if e.VelocityX < 0 {
+		e.Direction = 'l'
+	} else {
+		e.Direction = 'r'
+	}
+}
+
+// Checks if there is nothing to stand on in front of the entity
+func (g *Game) ledgeAhead(e *Entity) bool {
+	box := e.Box()
+	front := box.MaxX + skin
+	if e.VelocityX < 0 {
+		front = box.MinX - skin
+	}
+	probe := AABB{front - skin, box.MaxY - skin, front + skin, box.MaxY - skin}.Translate(e.VelocityX*FixedDelta, 0)
+	_, ok := g.Sweep(probe, 0, 0.5, true)
+	return !ok
+}
+
+// Checks if two boxes overlap
+func overlaps(a, b AABB) bool {
+	return a.MinX < b.MaxX && a.MaxX > b.MinX && a.MinY < b.MaxY && a.MaxY > b.MinY
+}
@@ -1,43 +1,143 @@
 package game
 
-// Describe the image of an block, an entity, an object
-type ImagePosition struct {
-	X1 int
-	X2 int
-	Y1 int
-	Y2 int
-}
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
 
 type Position struct {
-	X float64
-	Y float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
 }
 
 type Game struct {
-	BlockSize int            // Square size of blocks
-	width     int            // Number of blocks (width)
-	height    int            // Number of blocks (height)
-	AllBlocks map[rune]Block // All blocks
-	GameMap   [][]rune       // Game map
-	Player    Player         // Player in the map
-	Jump      int            // Indicator for long press ArrowKeyUp for short and long jumps
-}
-
-// Create all the structures and arrays to initialize the game
-func InitGame(xPlayerFixed int) Game {
-	// Init game structure
+	BlockSize  int            // Pixels of the sprite sheet for a block (cell size of the atlas)
+	width      int            // Number of blocks (width)
+	height     int            // Number of blocks (height)
+	AllBlocks  map[rune]Block // All blocks
+	GameMap    [][]rune       // Game map (collision layer)
+	Background [][]rune       // Decoration layer drawn behind the game map
+	Foreground [][]rune       // Decoration layer drawn over the player
+	Player     Player         // Player in the map
+	Spawn      Position       // Where the player comes back when killed
+	Entities   []Entity       // Enemies and other entities moving in the map
+	Jump       float64        // Time the jump key has been held (seconds), for short and long jumps
+	Time       float64        // Time spent in the level (seconds)
+	Finished   bool           // True once the exit is reached, the game does not advance anymore
+	TimeLimit  float64        // Time to reach the exit (seconds), no limit if 0
+	World      World          // Levels of the game
+	Level      int            // Index of the level played in the world, -1 if the map is not in the world
+
+	MapWarnings []UnknownRuneError     // Unknown runes replaced by air when loading the map
+	Items       map[ItemID]ItemType    // Kinds of items the player can carry
+	LootTables  map[string][]LootEntry // Content that chests can give, by table name
+	Shops       map[string][]ShopOffer // Items sold in shops, by shop name
+	Atlas       Atlas                  // Frames of the sprite sheet, by name
+	Swing       Swing                  // Melee hit of the player
+	Projectiles []Projectile           // Projectiles thrown by the player
+	Mining      Mining                 // Block the player is mining
+
+	config        Config        // Settings the game was initialized with
+	timeInAir     float64       // Time the player has been falling (seconds)
+	accumulator   float64       // Time not simulated yet by fixed steps (seconds)
+	entityMap     [][]rune      // Entity layer of the map, as it was loaded
+	layerStart    map[Layer]int // Line of the map file where each layer starts
+	originalMap   [][]rune      // Game map as it was loaded, to save only changes
+	startSpawn    Position      // Spawn of the player when the map was loaded
+	startEntities []Entity      // Entities when the map was loaded
+	startPlayer   Player        // Player's stats when the level started
+	mapHash       string        // Hash of the map file, to identify it in recordings
+	blocksHash    string        // Hash of the blocks definitions file
+	events        []Event       // Events not polled yet
+	seed          uint64        // State of the random generator
+	interactHeld  bool          // True if the interact key was pressed at the last step
+	attackHeld    bool          // True if the attack key was pressed at the last step
+	placeHeld     bool          // True if the place key was pressed at the last step
+}
+
+// Settings to initialize a game
+type Config struct {
+	MapPath    string // Path of the map file
+	LevelsPath string // Path of the levels manifest, no other level than the map if empty
+	BlocksPath string // Path of the blocks definitions file
+	AtlasPath  string // Path of the atlas manifest, naming the frames of the sprite sheet
+	Strict     bool   // Refuse a map with unknown blocks instead of replacing them by air
+	SaveDir    string // Directory of save files
+}
+
+// Default settings, playing the main map
+func DefaultConfig() Config {
+	return Config{DefaultMapPath, DefaultLevelsPath, DefaultBlocksPath, DefaultAtlasPath, false, DefaultSaveDir}
+}
+
+// Create all the structures and arrays to initialize the game with the map file
+func InitGame(config Config) (Game, error) {
+	game, err := newGame(config)
+	if err != nil {
+		return game, err
+	}
+	if err := game.createMap(config.MapPath, config.Strict); err != nil {
+		return game, err
+	}
+	if config.LevelsPath != "" {
+		if game.World, err = LoadWorld(config.LevelsPath); err != nil {
+			return game, err
+		}
+		if game.Level = game.World.find(config.MapPath); game.Level >= 0 {
+			game.TimeLimit = game.World.Levels[game.Level].TimeLimit
+		}
+	}
+	return game, game.checkSpawn()
+}
+
+// Create all the structures and arrays to initialize the game with a txt map,
+// config.MapPath is ignored
+func InitGameFromReader(config Config, r io.Reader) (Game, error) {
+	game, err := newGame(config)
+	if err != nil {
+		return game, err
+	}
+	if err := game.readMap(r, config.Strict); err != nil {
+		return game, err
+	}
+	return game, game.checkSpawn()
+}
+
+// Init game structure with all blocks loaded, but an empty map
+func newGame(config Config) (Game, error) {
 	game := Game{
-		64,
-		0,
-		0,
-		map[rune]Block{},
-		[][]rune{},
-		initPlayer(xPlayerFixed),
-		0,
+		AllBlocks:     map[rune]Block{},
+		GameMap:       [][]rune{},
+		Background:    [][]rune{},
+		Foreground:    [][]rune{},
+		Player:        initPlayer(),
+		Entities:      []Entity{},
+		Level:         -1,
+		MapWarnings:   []UnknownRuneError{},
+		Items:         map[ItemID]ItemType{},
+		LootTables:    map[string][]LootEntry{},
+		Shops:         map[string][]ShopOffer{},
+		Projectiles:   []Projectile{},
+		config:        config,
+		entityMap:     [][]rune{},
+		layerStart:    map[Layer]int{},
+		originalMap:   [][]rune{},
+		startEntities: []Entity{},
+		events:        []Event{},
+		seed:          uint64(time.Now().UnixNano()),
+	}
+	atlas, err := LoadAtlas(config.AtlasPath)
+	if err != nil {
+		return game, err
 	}
-	game.loadResources()
-	game.createMap()
-	return game
+	game.Atlas = atlas
+	game.BlockSize = atlas.CellSize
+	err = game.loadResources(config.BlocksPath)
+	return game, err
 }
 
 ///////////////////
@@ -62,139 +162,99 @@ func (g Game) GetEatBoxPoints() (xUpLeft, yUpLeft, xUpRight, yUpRight,
 	return
 }
 
-// Moves the player (checking if space is available)
+// Moves the player (checking if space is available), sliding along blocks
+// and stopping flush against them
 func (g *Game) Move(x, y float64) (moving bool) {
-
-	xUpLeft := int(g.Player.Position.X + g.Player.EatBox[0][0] + x)
-	yUpLeft := int(g.Player.Position.Y + g.Player.EatBox[0][1] + y)
-	var bUpLeft Block
-	if g.outOfMap([]int{xUpLeft}, []int{yUpLeft}) {
-		bUpLeft = g.AllBlocks[' ']
-	} else {
-		bUpLeft = g.AllBlocks[g.GameMap[xUpLeft][yUpLeft]]
-	}
-
-	xUpRight := int(g.Player.Position.X + g.Player.EatBox[1][0] + x)
-	yUpRight := int(g.Player.Position.Y + g.Player.EatBox[1][1] + y)
-	var bUpRight Block
-	if g.outOfMap([]int{xUpRight}, []int{yUpRight}) {
-		bUpRight = g.AllBlocks[' ']
-	} else {
-		bUpRight = g.AllBlocks[g.GameMap[xUpRight][yUpRight]]
-	}
-
-	xDownRight := int(g.Player.Position.X + g.Player.EatBox[2][0] + x)
-	yDownRight := int(g.Player.Position.Y + g.Player.EatBox[2][1] + y)
-	var bDownRight Block
-	if g.outOfMap([]int{xDownRight}, []int{yDownRight}) {
-		bDownRight = g.AllBlocks[' ']
-	} else {
-		bDownRight = g.AllBlocks[g.GameMap[xDownRight][yDownRight]]
-	}
-
-	xDownLeft := int(g.Player.Position.X + g.Player.EatBox[3][0] + x)
-	yDownLeft := int(g.Player.Position.Y + g.Player.EatBox[3][1] + y)
-	var bDownLeft Block
-	if g.outOfMap([]int{xDownLeft}, []int{yDownLeft}) {
-		bDownLeft = g.AllBlocks[' ']
-	} else {
-		bDownLeft = g.AllBlocks[g.GameMap[xDownLeft][yDownLeft]]
-	}
-
 	g.Collect() // Collect items if player is on collectable item
 
 	g.Action() // Do action (like open a door with a key)
 
-	if x > 0 {
-		if xUpLeft < g.width && xDownLeft < g.width {
-			if bUpRight.Solidity == NotSolid && bDownRight.Solidity == NotSolid {
-				g.Player.Move(x, 0.0)
-				if g.Player.TouchingGround {
-					moving = true
-				}
-			}
+	if x != 0 {
+		hit, blocked := g.Sweep(g.Player.Box(), x, 0, false)
+		if blocked {
+			x = flushMove(x, hit.Time)
 		}
-	} else if x < 0 {
-		if xUpRight >= 0 && xDownRight >= 0 {
-			if bUpLeft.Solidity == NotSolid && bDownLeft.Solidity == NotSolid {
-				g.Player.Move(x, 0.0)
-				if g.Player.TouchingGround {
-					moving = true
-				}
+		if x != 0 {
+			g.Player.Move(x, 0.0)
+			if g.Player.TouchingGround && !blocked {
+				moving = true
 			}
 		}
 	}
 
-	if y > 0 {
-		if yUpLeft < g.height && yUpRight < g.height {
-			if bDownLeft.Solidity == NotSolid && bDownRight.Solidity == NotSolid {
-				g.Player.Move(0.0, y)
-				moving = true
-			} else {
+	// Player does not fall further once out of the bottom of the map
+	if y > 0 && g.Player.Box().MinY >= float64(g.height) {
+		return
+	}
+
+	if y != 0 {
+		hit, blocked := g.Sweep(g.Player.Box(), 0, y, g.Player.DropTime <= 0)
+		if blocked {
+			y = flushMove(y, hit.Time)
+			if hit.NormalY < 0 {
 				// If player hits the ground
 				g.Player.TouchingGround = true
-				g.Player.VerticalVelocity = 0.0 // Reset the velocity of player
-			}
-		}
-	} else if y < 0 {
-		if yDownRight >= 0 && yDownLeft >= 0 {
-			if bUpLeft.Solidity == NotSolid && bUpRight.Solidity == NotSolid {
-				g.Player.Move(0.0, y)
-				moving = true
-			} else {
-				// If player hit a ceil
-				g.Player.VerticalVelocity = 0 // Reset of its velocity
 			}
+			g.Player.VerticalVelocity = 0.0 // Reset the velocity of player (ground or ceil)
+		} else {
+			moving = true
 		}
+		g.Player.Move(0.0, y)
 	}
 
 	return
 }
 
+// Returns the part of a move done before a hit, keeping a small gap with the block
+func flushMove(d, time float64) float64 {
+	d *= time
+	if d > 0 {
+		return math.Max(0, d-skin)
+	}
+	return math.Min(0, d+skin)
+}
+
 // Checks if player is over a collectable item, if yes, collects it
+// (items are kept in the map when the inventory is full)
 func (g *Game) Collect() {
 	x := int(g.Player.Position.X)
 	y := int(g.Player.Position.Y)
 	if !g.outOfMap([]int{x}, []int{y}) {
 		b := g.AllBlocks[g.GameMap[x][y]]
 		if b.Collectable {
-			g.GameMap[x][y] = ' '
-			switch b.Short {
-			case 'c':
+			switch {
+			case b.Short == 'c':
 				g.Player.CollectGold(1)
-			case 'k':
-				g.Player.Keys++
+				g.GameMap[x][y] = ' '
+			case b.Item != "":
+				if g.AddItem(b.Item, 1) > 0 {
+					g.GameMap[x][y] = ' '
+				}
 			}
 		}
 	}
 }
 
-// Checks if player is over an element that has an action and does it
+// Checks if player is next to an element that has an action and does it
 func (g *Game) Action() {
-	x := int(g.Player.Position.X)
-	y := int(g.Player.Position.Y)
-
-	// Block directly at the left of player
-	if !g.outOfMap([]int{x - 1}, []int{y}) {
-		b := g.AllBlocks[g.GameMap[x-1][y]]
-
-		// If blocks is a closed door
-		if b.Short == 'C' && g.Player.Keys > 0 {
-			g.GameMap[x-1][y] = 'O'
-			g.Player.Keys--
-		}
+	// Opens a closed door with a key
+	if slot := g.findUsable(OpenDoor); slot >= 0 && g.openDoor() {
+		g.removeItem(slot)
 	}
+}
 
-	// Block directly at the right of player
-	if !g.outOfMap([]int{x + 1}, []int{y}) {
-		b := g.AllBlocks[g.GameMap[x+1][y]]
-
-		// If blocks is a closed door
-		if b.Short == 'C' && g.Player.Keys > 0 {
-			g.GameMap[x+1][y] = 'O'
-			g.Player.Keys--
+// Opens a closed door directly at the left or at the right of the player,
+// returns true if a door was opened
+func (g *Game) openDoor() bool {
+	x := int(g.Player.Position.X)
+	y := int(g.Player.Position.Y)
+	for _, side := range []int{x - 1, x + 1} {
+		if !g.outOfMap([]int{side}, []int{y}) && g.GameMap[side][y] == 'C' {
+			g.GameMap[side][y] = 'O'
+			return true
 		}
 	}
+	return false
 }
 
 // Checks if coordinates are inside the map to not get an error out of bounds
@@ -222,11 +282,17 @@ func (g *Game) outOfMap(x []int, y []int) bool {
 // OTHER FUNCTIONS //
 /////////////////////
 
+// Returns the hash of a file content, used to identify maps and blocks definitions
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
 // Find the longest string of an array (used for get the width of the map depending on)
 func longestStr(arr []string) (max int) {
 	for _, v := range arr {
-		if len(v) > max {
-			max = len(v)
+		if utf8.RuneCountInString(v) > max {
+			max = utf8.RuneCountInString(v)
 		}
 	}
 	return
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Paths of the default config are relative to the root of the repository,
+// where the game is run from
+func TestMain(m *testing.M) {
+	if err := os.Chdir(".."); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// Creates a game with the default blocks from a txt map
+func newTestGame(t *testing.T, gameMap string) Game {
+	t.Helper()
+	g, err := InitGameFromReader(DefaultConfig(), strings.NewReader(gameMap))
+	if err != nil {
+		t.Fatalf("cannot create the game: %v", err)
+	}
+	return g
+}
+
+// Runs physics steps with the same input
+func (g *Game) steps(n int, input InputState) {
+	for i := 0; i < n; i++ {
+		g.Step(input)
+	}
+}
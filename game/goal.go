@@ -0,0 +1,90 @@
+package game
+
+import "math"
+
+// Trigger enum, what happens when the player touches a block
+type Trigger string
+
+const (
+	Start      Trigger = "Start"      // Player's spawn point when the level starts
+	Checkpoint Trigger = "Checkpoint" // Player comes back here when killed
+	Exit       Trigger = "Exit"       // Completes the level
+)
+
+// EventKind enum
+type EventKind string
+
+const (
+	CheckpointReached EventKind = "CheckpointReached"
+	LevelComplete     EventKind = "LevelComplete"
+	TimeUp            EventKind = "TimeUp"        // The level started again
+	ChestOpened       EventKind = "ChestOpened"   // The loot was given to the player
+	InventoryFull     EventKind = "InventoryFull" // A chest stays closed, its loot does not fit
+	ShopOpened        EventKind = "ShopOpened"    // The player wants to buy in a shop
+)
+
+// Something that happened in the game, for the window to react to
+type Event struct {
+	Kind     EventKind
+	Position Position   // Block where it happened
+	Stats    LevelStats // Stats of the level (LevelComplete only)
+	Loot     LootEntry  // Content of the chest (ChestOpened and InventoryFull only)
+	Shop     string     // Name of the shop (ShopOpened only)
+}
+
+// Player's results at the end of a level
+type LevelStats struct {
+	Time       float64 // Time spent in the level (seconds)
+	Coins      int     // Coins collected
+	TotalCoins int     // Coins in the level
+	Keys       int     // Keys owned
+}
+
+// Checks if the player touches a checkpoint or the exit
+func (g *Game) stepGoals() {
+	box := g.Player.Box()
+	for x := int(math.Floor(box.MinX)); x <= int(math.Floor(box.MaxX)); x++ {
+		for y := int(math.Floor(box.MinY)); y <= int(math.Floor(box.MaxY)); y++ {
+			if g.outOfMap([]int{x}, []int{y}) {
+				continue
+			}
+			cell := Position{float64(x) + 0.5, float64(y) + 0.5}
+			switch g.AllBlocks[g.GameMap[x][y]].Trigger {
+			case Checkpoint:
+				if g.Spawn != cell {
+					g.Spawn = cell
+					g.events = append(g.events, Event{Kind: CheckpointReached, Position: cell})
+				}
+			case Exit:
+				g.Finished = true
+				g.events = append(g.events, Event{Kind: LevelComplete, Position: cell, Stats: g.Stats()})
+				return
+			}
+		}
+	}
+}
+
+// Returns the player's results in the level so far
+func (g *Game) Stats() LevelStats {
+	total := countCoins(g.originalMap)
+	return LevelStats{g.Time, total - countCoins(g.GameMap), total, g.CountItem(KeyItem)}
+}
+
+// Returns the events that happened since the last call
+func (g *Game) PollEvents() []Event {
+	events := g.events
+	g.events = nil
+	return events
+}
+
+// Counts the coins left in a layer of the map
+func countCoins(layer [][]rune) (coins int) {
+	for x := range layer {
+		for _, c := range layer[x] {
+			if c == 'c' {
+				coins++
+			}
+		}
+	}
+	return
+}
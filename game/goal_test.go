@@ -0,0 +1,41 @@
+package game
+
+import "testing"
+
+func TestGoalEvents(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s      s
+s S F Es
+ssssssss
+[entities]
+`)
+	g.steps(stepsPerSecond/2, InputState{})
+	if events := g.PollEvents(); len(events) != 0 {
+		t.Errorf("got events %v at the start", events)
+	}
+
+	g.steps(stepsPerSecond/2, InputState{Right: true})
+	events := g.PollEvents()
+	if len(events) != 1 || events[0].Kind != CheckpointReached || events[0].Position != (Position{4.5, 1.5}) {
+		t.Fatalf("got events %v, expected the checkpoint to be reached", events)
+	}
+	if g.Spawn != events[0].Position {
+		t.Errorf("spawn is %v, expected the checkpoint", g.Spawn)
+	}
+
+	g.steps(stepsPerSecond, InputState{Right: true})
+	events = g.PollEvents()
+	if len(events) != 1 || events[0].Kind != LevelComplete || !g.Finished {
+		t.Fatalf("got events %v, expected the level to be complete", events)
+	}
+	if events[0].Stats.Time != g.Time {
+		t.Errorf("level complete after %.2fs, stats say %.2fs", g.Time, events[0].Stats.Time)
+	}
+
+	// Nothing moves once the level is finished
+	position := g.Player.Position
+	g.steps(stepsPerSecond, InputState{Left: true})
+	if g.Player.Position != position || len(g.PollEvents()) != 0 {
+		t.Error("game goes on after the level is complete")
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import "math"
+
+const startHealth int = 3            // Hearts of the player
+const startLives int = 3             // Lives of the player before restarting the level
+const invulnerableTime float64 = 1.5 // Time without taking damage after a hit (seconds)
+
+// Manages damages taken by the player during a physics step
+func (g *Game) stepHealth() {
+	if g.Player.Invulnerable > 0 {
+		g.Player.Invulnerable -= FixedDelta
+	}
+
+	// Falling out of the world
+	box := g.Player.Box()
+	if box.MinY >= float64(g.height) {
+		g.killPlayer()
+		return
+	}
+
+	// Touching hazard blocks
+	for x := int(math.Floor(box.MinX)); x <= int(math.Floor(box.MaxX)); x++ {
+		for y := int(math.Floor(box.MinY)); y <= int(math.Floor(box.MaxY)); y++ {
+			if g.outOfMap([]int{x}, []int{y}) {
+				continue
+			}
+			if b := g.AllBlocks[g.GameMap[x][y]]; b.Damage > 0 {
+				g.HurtPlayer(b.Damage)
+			}
+		}
+	}
+}
+
+// Removes hearts of the player unless invulnerable, kills the player if none is left
+func (g *Game) HurtPlayer(damage int) {
+	if g.Player.Invulnerable > 0 {
+		return
+	}
+	g.Player.Health -= damage
+	if g.Player.Health <= 0 {
+		g.killPlayer()
+	} else {
+		g.Player.Invulnerable = invulnerableTime
+	}
+}
+
+// Kills the player, who loses a life and comes back at the last checkpoint,
+// the level restarts when no life is left
+func (g *Game) killPlayer() {
+	g.Player.Lives--
+	if g.Player.Lives <= 0 {
+		g.restartLevel()
+	}
+	g.respawn()
+}
+
+// Brings the player back at the last checkpoint, with all hearts
+func (g *Game) respawn() {
+	g.Player.Position = g.Spawn
+	g.Player.Health = g.Player.MaxHealth
+	g.Player.Invulnerable = invulnerableTime
+	g.Player.VerticalVelocity = 0
+	g.Player.TouchingGround = false
+	g.Player.DropTime = 0
+	g.Jump = 0
+	g.timeInAir = 0
+}
+
+// Puts the map back as it was loaded, and the player's stats as when starting
+func (g *Game) restartLevel() {
+	g.GameMap = copyLayer(g.originalMap)
+	g.Entities = append([]Entity{}, g.startEntities...)
+	g.Spawn = g.startSpawn
+	g.Player.Lives = startLives
+	g.Time = 0
+	g.Player.Gold = g.startPlayer.Gold
+	g.Player.Inventory = append([]ItemStack{}, g.startPlayer.Inventory...)
+	g.Player.Weapon = g.startPlayer.Weapon
+	g.Player.Block = g.startPlayer.Block
+	g.Mining = Mining{}
+	g.Swing = Swing{}
+	g.Projectiles = []Projectile{}
+}
@@ -0,0 +1,73 @@
+package game
+
+import "testing"
+
+// Map with spikes on the right of the spawn, and a hole on the left
+const hazardTestMap string = `[collision]
+s      s
+s  ^   s
+ss sssss
+[entities]
+
+   p
+`
+
+func TestHazards(t *testing.T) {
+	tests := []struct {
+		name         string
+		health       int
+		lives        int
+		invulnerable float64
+		healthLeft   int
+		livesLeft    int
+		position     Position // Where the player is after the step
+	}{
+		{"hurt", 3, 3, 0, 2, 3, Position{3.5, 1.5}},
+		{"invulnerable", 3, 3, 1, 3, 3, Position{3.5, 1.5}},
+		{"last heart", 1, 3, 0, startHealth, 2, Position{5.5, 1.5}},         // At the checkpoint
+		{"last life", 1, 1, 0, startHealth, startLives, Position{3.5, 1.5}}, // At the start of the level
+	}
+
+	for _, test := range tests {
+		g := newTestGame(t, hazardTestMap)
+		g.Spawn = Position{5.5, 1.5} // Checkpoint
+		g.Player.Health = test.health
+		g.Player.Lives = test.lives
+		g.Player.Invulnerable = test.invulnerable
+		g.Step(InputState{})
+
+		if g.Player.Health != test.healthLeft || g.Player.Lives != test.livesLeft {
+			t.Errorf("%s: %d hearts and %d lives, expected %d and %d",
+				test.name, g.Player.Health, g.Player.Lives, test.healthLeft, test.livesLeft)
+		}
+		if g.Player.Position.X != test.position.X {
+			t.Errorf("%s: player at %v, expected %v", test.name, g.Player.Position, test.position)
+		}
+		if test.healthLeft != test.health && g.Player.Invulnerable <= 0 {
+			t.Errorf("%s: player is not invulnerable after a hit", test.name)
+		}
+	}
+}
+
+func TestFallingOutOfTheMap(t *testing.T) {
+	g := newTestGame(t, hazardTestMap)
+	g.Player.Position = Position{2.5, 1.5}
+	g.steps(stepsPerSecond, InputState{})
+	if g.Player.Lives != startLives-1 || g.Player.Position != g.Spawn {
+		t.Errorf("player at %v with %d lives, expected to lose a life and respawn", g.Player.Position, g.Player.Lives)
+	}
+}
+
+func TestGameOverRestartsTheLevel(t *testing.T) {
+	g := newTestGame(t, hazardTestMap)
+	g.Player.Gold = 4
+	g.startPlayer.Gold = 1
+	g.GameMap[1][1] = 'c'
+	g.Spawn = Position{5.5, 1.5}
+	g.Player.Lives = 1
+	g.killPlayer()
+
+	if g.Player.Gold != 1 || g.GameMap[1][1] != ' ' || g.Spawn != g.startSpawn || g.Player.Lives != startLives {
+		t.Error("level is not restarted after losing the last life")
+	}
+}
@@ -0,0 +1,69 @@
+package game
+
+// State of the player's controls for a single frame, filled by the window
+// (keyboard) or by any other driver (tests, bots, replays)
+type InputState struct {
+	Left     bool // Walk to the left
+	Right    bool // Walk to the right
+	Jump     bool // Jump, holding it turns a short jump into a long one
+	Down     bool // With jump, drops through the platform under the player
+	Interact bool // Opens the chest the player is next to
+	Attack   bool // Attacks with the weapon in use
+	Mine     bool // Mines the block in front of the player, while held
+	Place    bool // Places the block held in front of the player
+}
+
+// Bits of each control when packing an input in a byte (for recordings)
+const (
+	inputLeft uint8 = 1 << iota
+	inputRight
+	inputJump
+	inputDown
+	inputInteract
+	inputAttack
+	inputMine
+	inputPlace
+)
+
+// Packs the input in a byte
+func (i InputState) bits() (b uint8) {
+	if i.Left {
+		b |= inputLeft
+	}
+	if i.Right {
+		b |= inputRight
+	}
+	if i.Jump {
+		b |= inputJump
+	}
+	if i.Down {
+		b |= inputDown
+	}
+	if i.Interact {
+		b |= inputInteract
+	}
+	if i.Attack {
+		b |= inputAttack
+	}
+	if i.Mine {
+		b |= inputMine
+	}
+	if i.Place {
+		b |= inputPlace
+	}
+	return
+}
+
+// Unpacks an input packed in a byte
+func inputFromBits(b uint8) InputState {
+	return InputState{
+		Left:     b&inputLeft != 0,
+		Right:    b&inputRight != 0,
+		Jump:     b&inputJump != 0,
+		Down:     b&inputDown != 0,
+		Interact: b&inputInteract != 0,
+		Attack:   b&inputAttack != 0,
+		Mine:     b&inputMine != 0,
+		Place:    b&inputPlace != 0,
+	}
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+const inventorySize int = 8  // Number of slots of the inventory
+const KeyItem ItemID = "key" // Item opening closed doors
+const healAmount int = 1     // Hearts given back by a healing item
+
+var ErrCannotUse = errors.New("item cannot be used now")
+
+// Identifier of an item type, as written in the blocks definitions file
+type ItemID string
+
+// UseAction enum
+type UseAction string
+
+const (
+	OpenDoor UseAction = "OpenDoor" // Opens a closed door next to the player
+	Heal     UseAction = "Heal"     // Gives back a heart
+	Equip    UseAction = "Equip"    // Attacks with the item (a weapon), it is not consumed
+	Place    UseAction = "Place"    // Places the item as a block, one is used by block placed
+)
+
+// Kind of item the player can carry
+type ItemType struct {
+	ID          ItemID
+	Name        string
+	Description string
+	StackLimit  int       // Most items of this type in a single slot
+	Use         UseAction // What using the item does, nothing if empty
+	Icon        string    // Atlas frame of the icon of the item
+	Weapon      Weapon    // Stats of the item when used to attack
+	Block       rune      // Block placed with the item
+}
+
+// Slot of the inventory, holding items of the same type
+type ItemStack struct {
+	Item  ItemID
+	Count int
+}
+
+// Returned when an item is not in the item types
+type UnknownItemError struct {
+	Item ItemID
+}
+
+func (e *UnknownItemError) Error() string {
+	return fmt.Sprintf("unknown item %q", e.Item)
+}
+
+// Adds items to the inventory, filling existing stacks first,
+// returns how many were added (the inventory may be full)
+func (g *Game) AddItem(id ItemID, count int) (added int) {
+	limit := g.Items[id].StackLimit
+	if limit <= 0 {
+		return // Unknown item
+	}
+	for i := range g.Player.Inventory {
+		s := &g.Player.Inventory[i]
+		if s.Item == id && s.Count < limit {
+			n := limit - s.Count
+			if n > count-added {
+				n = count - added
+			}
+			s.Count += n
+			added += n
+		}
+	}
+	for added < count && len(g.Player.Inventory) < inventorySize {
+		n := limit
+		if n > count-added {
+			n = count - added
+		}
+		g.Player.Inventory = append(g.Player.Inventory, ItemStack{id, n})
+		added += n
+	}
+
+	// First weapon and first block collected are used right away
+	if added > 0 && g.Player.Weapon == "" && g.Items[id].Use == Equip {
+		g.Player.Weapon = id
+	}
+	if added > 0 && g.Player.Block == "" && g.Items[id].Use == Place {
+		g.Player.Block = id
+	}
+	return
+}
+
+// Returns the number of items of a type in the inventory
+func (g *Game) CountItem(id ItemID) (count int) {
+	for _, s := range g.Player.Inventory {
+		if s.Item == id {
+			count += s.Count
+		}
+	}
+	return
+}
+
+// Removes one item of a slot, the slot is freed when empty
+func (g *Game) removeItem(slot int) {
+	g.Player.Inventory[slot].Count--
+	if g.Player.Inventory[slot].Count <= 0 {
+		g.Player.Inventory = append(g.Player.Inventory[:slot], g.Player.Inventory[slot+1:]...)
+	}
+}
+
+// Returns the first slot holding an item with a use action, -1 if none
+func (g *Game) findUsable(use UseAction) int {
+	for i, s := range g.Player.Inventory {
+		if g.Items[s.Item].Use == use {
+			return i
+		}
+	}
+	return -1
+}
+
+// Uses an item of the inventory, which is consumed if it had an effect
+func (g *Game) UseItem(slot int) error {
+	if slot < 0 || slot >= len(g.Player.Inventory) {
+		return ErrCannotUse
+	}
+	switch g.Items[g.Player.Inventory[slot].Item].Use {
+	case OpenDoor:
+		if !g.openDoor() {
+			return ErrCannotUse
+		}
+	case Heal:
+		if g.Player.Health >= g.Player.MaxHealth {
+			return ErrCannotUse
+		}
+		g.Player.Health += healAmount
+		if g.Player.Health > g.Player.MaxHealth {
+			g.Player.Health = g.Player.MaxHealth
+		}
+	case Equip:
+		g.Player.Weapon = g.Player.Inventory[slot].Item
+		return nil
+	case Place:
+		g.Player.Block = g.Player.Inventory[slot].Item
+		return nil
+	default:
+		return ErrCannotUse
+	}
+	g.removeItem(slot)
+	return nil
+}
@@ -0,0 +1,111 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory []ItemStack
+		item      ItemID
+		count     int
+		added     int
+		slots     []ItemStack // Inventory after adding
+	}{
+		{"empty inventory", nil, "potion", 1, 1, []ItemStack{{"potion", 1}}},
+		{"existing stack", []ItemStack{{"potion", 1}}, "potion", 1, 1, []ItemStack{{"potion", 2}}},
+		{"over the stack limit", []ItemStack{{"potion", 2}}, "potion", 5, 5,
+			[]ItemStack{{"potion", 3}, {"potion", 3}, {"potion", 1}}},
+		{"unknown item", nil, "gem", 1, 0, nil},
+		{"full inventory", fullOf("sword", inventorySize), "potion", 1, 0, fullOf("sword", inventorySize)},
+		{"partly full", append(fullOf("sword", inventorySize-1), ItemStack{"key", 8}), "key", 3, 1,
+			append(fullOf("sword", inventorySize-1), ItemStack{"key", 9})},
+	}
+
+	for _, test := range tests {
+		g := newTestGame(t, flatTestMap)
+		g.Player.Inventory = append([]ItemStack{}, test.inventory...)
+		if added := g.AddItem(test.item, test.count); added != test.added {
+			t.Errorf("%s: added %d items, expected %d", test.name, added, test.added)
+		}
+		if !sameSlots(g.Player.Inventory, test.slots) {
+			t.Errorf("%s: inventory is %v, expected %v", test.name, g.Player.Inventory, test.slots)
+		}
+	}
+}
+
+func TestFirstWeaponAndBlockAreHeld(t *testing.T) {
+	g := newTestGame(t, flatTestMap)
+	g.AddItem("sword", 1)
+	g.AddItem("sling", 1)
+	g.AddItem("dirt", 2)
+	if g.Player.Weapon != "sword" || g.Player.Block != "dirt" {
+		t.Errorf("holding weapon %q and block %q, expected the first ones collected", g.Player.Weapon, g.Player.Block)
+	}
+}
+
+func TestUseItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     ItemID
+		health   int
+		expected error
+		consumed bool
+	}{
+		{"heal", "potion", 1, nil, true},
+		{"heal at full health", "potion", startHealth, ErrCannotUse, false},
+		{"key without door", "key", startHealth, ErrCannotUse, false},
+		{"equip", "sling", startHealth, nil, false},
+		{"hold a block", "dirt", startHealth, nil, false},
+	}
+
+	for _, test := range tests {
+		g := newTestGame(t, flatTestMap)
+		g.Player.Inventory = []ItemStack{{"potion", 1}, {test.item, 1}}
+		g.Player.Health = test.health
+
+		err := g.UseItem(1)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s: got error %v, expected %v", test.name, err, test.expected)
+		}
+		if consumed := len(g.Player.Inventory) == 1; consumed != test.consumed {
+			t.Errorf("%s: inventory is %v, consumed is %v", test.name, g.Player.Inventory, test.consumed)
+		}
+	}
+
+	g := newTestGame(t, flatTestMap)
+	if err := g.UseItem(0); !errors.Is(err, ErrCannotUse) {
+		t.Errorf("empty slot: got error %v, expected %v", err, ErrCannotUse)
+	}
+}
+
+func TestUseItemEffects(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s   s
+s  Cs
+sssss
+[entities]
+
+ p
+`)
+	g.Player.Inventory = []ItemStack{{"potion", 2}, {"key", 1}, {"sling", 1}, {"dirt", 1}}
+	g.Player.Health = 1
+
+	if err := g.UseItem(0); err != nil || g.Player.Health != 1+healAmount || g.CountItem("potion") != 1 {
+		t.Errorf("potion: got error %v, %d hearts and %d potions left", err, g.Player.Health, g.CountItem("potion"))
+	}
+
+	g.Player.Position.X = 2.5 // Next to the door
+	if err := g.UseItem(1); err != nil || g.GameMap[3][1] != 'O' || g.CountItem(KeyItem) != 0 {
+		t.Errorf("key: got error %v, door is %q", err, g.GameMap[3][1])
+	}
+
+	if err := g.UseItem(1); err != nil || g.Player.Weapon != "sling" {
+		t.Errorf("sling: got error %v, weapon is %q", err, g.Player.Weapon)
+	}
+	if err := g.UseItem(2); err != nil || g.Player.Block != "dirt" {
+		t.Errorf("dirt: got error %v, block is %q", err, g.Player.Block)
+	}
+}
@@ -0,0 +1,184 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultLevelsPath string = "data/levels.json"
+
+const progressFile string = "progress.json" // File of the unlocked levels, in the save directory
+
+var ErrNoNextLevel = errors.New("no level after this one")
+
+// Returned when a level index is not in the world
+type LevelNotFoundError struct {
+	Index int
+}
+
+func (e *LevelNotFoundError) Error() string {
+	return fmt.Sprintf("no level #%d in the world", e.Index+1)
+}
+
+// Returned when the levels manifest is invalid, lists all problems
+type WorldValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *WorldValidationError) Error() string {
+	return fmt.Sprintf("invalid levels in %s: %s", e.Path, strings.Join(e.Problems, "; "))
+}
+
+// Levels of the game, played in order
+type World struct {
+	Levels []Level `json:"levels"`
+}
+
+// Settings of a level in the levels manifest
+type Level struct {
+	Name      string          `json:"name"`
+	MapPath   string          `json:"map"`
+	Parallax  []ParallaxLayer `json:"parallax"`  // Images drawn behind the map, from the farthest
+	TimeLimit float64         `json:"timeLimit"` // Time to reach the exit (seconds), no limit if 0
+}
+
+// Image drawn behind the map, moving slower than the map to look far away
+type ParallaxLayer struct {
+	Image   string  `json:"image"`
+	ScrollX float64 `json:"scrollX"` // Part of the camera moves followed horizontally, 0 does not move and 1 moves with the map
+	ScrollY float64 `json:"scrollY"` // Part of the camera moves followed vertically
+	Tile    bool    `json:"tile"`    // Repeats the image horizontally
+	OffsetY float64 `json:"offsetY"` // Shift of the image from the top of the view (pixels)
+}
+
+// Levels unlocked by the player, kept between games
+type Progress struct {
+	Unlocked int `json:"unlocked"` // Number of levels unlocked, from the first one
+}
+
+// Loads the levels manifest
+func LoadWorld(path string) (World, error) {
+	var world World
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return world, err
+	}
+	if err := json.Unmarshal(content, &world); err != nil {
+		return world, fmt.Errorf("cannot read levels in %s: %w", path, err)
+	}
+	if problems := world.validate(); len(problems) > 0 {
+		return world, &WorldValidationError{path, problems}
+	}
+	return world, nil
+}
+
+// Checks the levels of the manifest, returns every problem found
+func (w World) validate() (problems []string) {
+	if len(w.Levels) == 0 {
+		problems = append(problems, "no level")
+	}
+	for i, l := range w.Levels {
+		if l.Name == "" {
+			problems = append(problems, fmt.Sprintf("level #%d has no name", i+1))
+		}
+		if l.MapPath == "" {
+			problems = append(problems, fmt.Sprintf("level %q has no map", l.Name))
+		}
+		if l.TimeLimit < 0 {
+			problems = append(problems, fmt.Sprintf("level %q: timeLimit must be positive", l.Name))
+		}
+		for j, p := range l.Parallax {
+			if p.Image == "" {
+				problems = append(problems, fmt.Sprintf("level %q: parallax layer #%d has no image", l.Name, j+1))
+			}
+		}
+	}
+	return
+}
+
+// Returns the index of the level playing a map, -1 if the map is not in the world
+func (w World) find(mapPath string) int {
+	for i, l := range w.Levels {
+		if filepath.Clean(l.MapPath) == filepath.Clean(mapPath) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Goes to the level after the current one, keeping the player's stats or not
+func (g *Game) NextLevel(keepStats bool) error {
+	if g.Level < 0 || g.Level+1 >= len(g.World.Levels) {
+		return ErrNoNextLevel
+	}
+	return g.LoadLevel(g.Level+1, keepStats)
+}
+
+// Replaces the game by a level of the world, keeping the player's stats or not
+// (gold, inventory, weapon, block held and lives)
+func (g *Game) LoadLevel(index int, keepStats bool) error {
+	if index < 0 || index >= len(g.World.Levels) {
+		return &LevelNotFoundError{index}
+	}
+	config := g.config
+	config.MapPath = g.World.Levels[index].MapPath
+	next, err := InitGame(config)
+	if err != nil {
+		return err
+	}
+	if keepStats {
+		next.Player.Gold = g.Player.Gold
+		next.Player.Lives = g.Player.Lives
+		next.Player.Inventory = append([]ItemStack{}, g.Player.Inventory...)
+		next.Player.Weapon = g.Player.Weapon
+		next.Player.Block = g.Player.Block
+		next.startPlayer = next.Player
+		next.startPlayer.Inventory = append([]ItemStack{}, next.Player.Inventory...) // Not changed by the items collected
+	}
+	*g = next
+	return nil
+}
+
+// Restarts the level when the time limit is reached
+func (g *Game) stepTimeLimit() {
+	if g.TimeLimit > 0 && g.Time >= g.TimeLimit {
+		g.restartLevel()
+		g.respawn()
+		g.events = append(g.events, Event{Kind: TimeUp, Position: g.Player.Position})
+	}
+}
+
+// Loads the levels unlocked by the player, only the first one if nothing is saved
+func LoadProgress(saveDir string) (Progress, error) {
+	progress := Progress{1}
+	content, err := os.ReadFile(filepath.Join(saveDir, progressFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return progress, nil
+	} else if err != nil {
+		return progress, err
+	}
+	if err := json.Unmarshal(content, &progress); err != nil {
+		return progress, err
+	}
+	if progress.Unlocked < 1 {
+		progress.Unlocked = 1
+	}
+	return progress, nil
+}
+
+// Writes the levels unlocked by the player
+func (p Progress) Save(saveDir string) error {
+	content, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(saveDir, progressFile), content, 0644)
+}
@@ -0,0 +1,25 @@
+package game
+
+import "testing"
+
+func TestRestartKeepsTheStatsOfTheLevelStart(t *testing.T) {
+	g, err := InitGame(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.AddItem(KeyItem, 2)
+	g.Player.Gold = 7
+	if err := g.NextLevel(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Items collected in the level are lost when it starts again
+	g.AddItem(KeyItem, 3)
+	g.Player.Gold += 5
+	g.Player.Lives = 1
+	g.killPlayer()
+
+	if keys := g.CountItem(KeyItem); keys != 2 || g.Player.Gold != 7 {
+		t.Errorf("level restarted with %d keys and %d golds, expected 2 and 7", keys, g.Player.Gold)
+	}
+}
@@ -1,42 +1,352 @@
 package game
 
 import (
-	"io/ioutil"
-	"log"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
-const mapPath string = "data/maps/map.txt"
+const DefaultMapPath string = "data/maps/map.txt"
+
+// Name of a layer of the map
+type Layer string
+
+const (
+	BackgroundLayer Layer = "background" // Decoration drawn behind the blocks
+	CollisionLayer  Layer = "collision"  // Blocks the player walks on and interacts with
+	ForegroundLayer Layer = "foreground" // Decoration drawn over the player
+	EntityLayer     Layer = "entities"   // Spawn point and items
+)
+
+// Layers in drawing order
+var AllLayers = []Layer{BackgroundLayer, CollisionLayer, ForegroundLayer, EntityLayer}
+
+const spawnRune rune = 'p' // Player's spawn point in the entity layer (or in a map without layers)
+
+// Returned when the map file has no block at all
+var ErrEmptyMap = errors.New("map is empty")
+
+// Returned when the map file does not exist
+type MapNotFoundError struct {
+	Path string
+}
+
+func (e *MapNotFoundError) Error() string {
+	return fmt.Sprintf("map file not found: %s", e.Path)
+}
+
+// Returned when the map file contains a line that cannot be read
+type MapSyntaxError struct {
+	Line    int // Line in the map file, starting at 1
+	Message string
+}
+
+func (e *MapSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Returned when the map contains a rune that is not a registered block
+type UnknownRuneError struct {
+	Rune   rune
+	Layer  Layer
+	Line   int // Line in the map file, starting at 1
+	Column int // Column in the map file, starting at 1
+}
+
+func (e *UnknownRuneError) Error() string {
+	kind := "block"
+	if e.Layer == EntityLayer {
+		kind = "entity"
+	}
+	return fmt.Sprintf("unknown %s %q in %s layer at line %d, column %d", kind, e.Rune, e.Layer, e.Line, e.Column)
+}
+
+// Returned in strict mode when the map contains unknown runes, lists all of them
+type MapValidationError struct {
+	Unknown []UnknownRuneError
+}
+
+func (e *MapValidationError) Error() string {
+	msgs := []string{}
+	for _, u := range e.Unknown {
+		msgs = append(msgs, u.Error())
+	}
+	return fmt.Sprintf("%d unknown block(s) in map: %s", len(e.Unknown), strings.Join(msgs, "; "))
+}
+
+// Lets errors.As find the first unknown rune, as when a single one is returned
+func (e *MapValidationError) As(target interface{}) bool {
+	if t, ok := target.(**UnknownRuneError); ok && len(e.Unknown) > 0 {
+		*t = &e.Unknown[0]
+		return true
+	}
+	return false
+}
+
+// Returned when there is nothing to stand on under the player's spawn
+type NoGroundError struct {
+	Spawn Position
+}
+
+func (e *NoGroundError) Error() string {
+	return fmt.Sprintf("no ground under spawn at (%.1f, %.1f)", e.Spawn.X, e.Spawn.Y)
+}
 
 // Creates the map (array of array of runes) based on the txt map file
-func (game *Game) createMap() {
-	// Reads the resources file
-	file, err := ioutil.ReadFile(mapPath)
+func (game *Game) createMap(mapPath string, strict bool) error {
+	content, err := os.ReadFile(mapPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &MapNotFoundError{mapPath}
+		}
+		return err
+	}
+	game.mapHash = hashContent(content)
+
+	// Maps made with the Tiled editor
+	switch strings.ToLower(filepath.Ext(mapPath)) {
+	case ".tmj":
+		return game.readTiledJSON(bytes.NewReader(content), strict)
+	case ".tmx":
+		return game.readTiledXML(bytes.NewReader(content), strict)
+	}
+
+	return game.readMap(bytes.NewReader(content), strict)
+}
+
+// Creates the map (array of array of runes) based on a txt map.
+// In strict mode, unknown runes are refused, otherwise they are replaced by air
+// and listed in MapWarnings.
+//
+// A map is either a single grid of blocks (the collision layer), or several
+// layers, each one starting with its name between brackets on its own line:
+//
+//	[background]
+//	[collision]
+//	[foreground]
+//	[entities]
+func (game *Game) readMap(r io.Reader, strict bool) error {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	game.mapHash = hashContent(content)
+
+	// Takes all lines as a slice of strings (supports Windows line endings)
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	lines := strings.Split(text, "\n")
+
+	// Split lines by layer
+	layers, err := game.splitLayers(lines)
 	if err != nil {
-		log.Fatal(err) // /!\ Need a better handle of the error here /!\
-	} else {
-		// Takes all lines as a slice of strings
-		lines := strings.Split(string(file), "\n")
-
-		// Get the size of the map
-		game.height = len(lines)
-		game.width = longestStr(lines)
-
-		// Generate empty map
-		for w := 0; w < game.width; w++ {
-			game.GameMap = append(game.GameMap, []rune{})
-			for h := 0; h < game.height; h++ {
-				game.GameMap[w] = append(game.GameMap[w], ' ')
+		return err
+	}
+
+	// Get the size of the map
+	game.width, game.height = 0, 0
+	for _, l := range layers {
+		if longestStr(l) > game.width {
+			game.width = longestStr(l)
+		}
+		if len(l) > game.height {
+			game.height = len(l)
+		}
+	}
+	if game.width == 0 {
+		return ErrEmptyMap
+	}
+
+	// Generate and fill each layer
+	game.Background = newLayer(layers[BackgroundLayer], game.width, game.height)
+	game.GameMap = newLayer(layers[CollisionLayer], game.width, game.height)
+	game.Foreground = newLayer(layers[ForegroundLayer], game.width, game.height)
+	game.entityMap = newLayer(layers[EntityLayer], game.width, game.height)
+
+	return game.finishMap(strict)
+}
+
+// Validates the layers once filled and places the entities
+func (game *Game) finishMap(strict bool) error {
+	// Check all runes against the blocks registry
+	game.MapWarnings = game.ValidateMap()
+	if len(game.MapWarnings) > 0 {
+		if strict {
+			return &MapValidationError{game.MapWarnings}
+		}
+		for _, u := range game.MapWarnings {
+			game.layer(u.Layer)[u.Column-1][u.Line-1-game.layerStart[u.Layer]] = ' '
+		}
+	}
+
+	game.placeEntities()
+	game.originalMap = copyLayer(game.GameMap)
+	game.startSpawn = game.Spawn
+	game.startEntities = append([]Entity{}, game.Entities...)
+	game.startPlayer = game.Player
+	return nil
+}
+
+// Splits the lines of the map file by layer, remembering where each layer starts
+func (game *Game) splitLayers(lines []string) (map[Layer][]string, error) {
+	layers := map[Layer][]string{}
+	game.layerStart = map[Layer]int{}
+
+	var current Layer
+	for i, l := range lines {
+		if strings.HasPrefix(l, "[") && strings.HasSuffix(strings.TrimSpace(l), "]") {
+			current = Layer(strings.Trim(strings.TrimSpace(l), "[]"))
+			if !knownLayer(current) {
+				return nil, &MapSyntaxError{i + 1, fmt.Sprintf("unknown layer %q", current)}
+			}
+			if _, ok := game.layerStart[current]; ok {
+				return nil, &MapSyntaxError{i + 1, fmt.Sprintf("layer %q is defined twice", current)}
 			}
+			game.layerStart[current] = i + 1
+			layers[current] = []string{}
+		} else if current != "" {
+			layers[current] = append(layers[current], l)
+		} else if strings.TrimSpace(l) != "" {
+			// No layer header before the first blocks, the whole file is the collision layer
+			game.layerStart = map[Layer]int{CollisionLayer: 0}
+			return map[Layer][]string{CollisionLayer: lines}, nil
 		}
+	}
+	return layers, nil
+}
+
+// Checks if a layer name is one of the map layers
+func knownLayer(l Layer) bool {
+	for _, v := range AllLayers {
+		if v == l {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates a layer of the map, filled with air where lines are empty or too short
+func newLayer(lines []string, width, height int) [][]rune {
+	layer := [][]rune{}
+	for w := 0; w < width; w++ {
+		layer = append(layer, []rune{})
+		for h := 0; h < height; h++ {
+			layer[w] = append(layer[w], ' ')
+		}
+	}
 
-		// Fill the map
-		for x, l := range lines {
-			for y, c := range l {
-				if c != ' ' {
-					game.GameMap[y][x] = c
+	for y, l := range lines {
+		for x, c := range []rune(l) {
+			if c != ' ' {
+				layer[x][y] = c
+			}
+		}
+	}
+	return layer
+}
+
+// Returns a copy of a layer of the map
+func copyLayer(layer [][]rune) [][]rune {
+	copied := [][]rune{}
+	for _, column := range layer {
+		copied = append(copied, append([]rune{}, column...))
+	}
+	return copied
+}
+
+// Returns the grid of a layer of the map
+func (game *Game) layer(l Layer) [][]rune {
+	switch l {
+	case BackgroundLayer:
+		return game.Background
+	case CollisionLayer:
+		return game.GameMap
+	case ForegroundLayer:
+		return game.Foreground
+	case EntityLayer:
+		return game.entityMap
+	}
+	return nil
+}
+
+// Lists every rune of the map that has no entry in the blocks registry
+// (or that is not an entity in the entity layer)
+func (game *Game) ValidateMap() (unknown []UnknownRuneError) {
+	for _, l := range AllLayers {
+		grid := game.layer(l)
+		for y := 0; y < game.height && len(grid) > 0; y++ {
+			for x := 0; x < game.width; x++ {
+				c := grid[x][y]
+				if !game.knownRune(l, c) {
+					unknown = append(unknown, UnknownRuneError{c, l, game.layerStart[l] + y + 1, x + 1})
 				}
 			}
 		}
 	}
+	return
+}
+
+// Checks if a rune can be placed in a layer
+func (game *Game) knownRune(l Layer, c rune) bool {
+	b, ok := game.AllBlocks[c]
+	if l == EntityLayer {
+		return c == ' ' || c == spawnRune || (ok && (b.Collectable || b.Entity != "" || b.Trigger != ""))
+	}
+	return ok
+}
+
+// Places the entities of the entity layer into the game
+// (and the entities of the collision layer, for maps without layers),
+// puts the player at the spawn point or the start block
+func (game *Game) placeEntities() {
+	game.Entities = []Entity{}
+	for x := range game.entityMap {
+		for y, c := range game.entityMap[x] {
+			b := game.AllBlocks[c]
+			switch {
+			case c == spawnRune:
+				game.Player.Position = Position{float64(x) + 0.5, float64(y) + 0.5}
+			case b.Entity != "":
+				game.Entities = append(game.Entities, newEntity(b, x, y))
+			case b.Collectable || b.Trigger != "":
+				game.GameMap[x][y] = c
+			}
+		}
+	}
+
+	for x := range game.GameMap {
+		for y, c := range game.GameMap[x] {
+			b := game.AllBlocks[c]
+			switch {
+			case c == spawnRune:
+				game.Player.Position = Position{float64(x) + 0.5, float64(y) + 0.5}
+				game.GameMap[x][y] = ' '
+			case b.Entity != "":
+				game.Entities = append(game.Entities, newEntity(b, x, y))
+				game.GameMap[x][y] = ' '
+			case b.Trigger == Start:
+				game.Player.Position = Position{float64(x) + 0.5, float64(y) + 0.5}
+			}
+		}
+	}
+	game.Spawn = game.Player.Position
+}
+
+// Checks that the player would land on something when spawning
+func (game *Game) checkSpawn() error {
+	x := int(game.Player.Position.X)
+	y := int(game.Player.Position.Y)
+	if game.outOfMap([]int{x}, []int{y}) {
+		return &NoGroundError{game.Player.Position}
+	}
+	for ; y < game.height; y++ {
+		if game.AllBlocks[game.GameMap[x][y]].Solidity != NotSolid {
+			return nil
+		}
+	}
+	return &NoGroundError{game.Player.Position}
 }
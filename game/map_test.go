@@ -0,0 +1,110 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMapErrors(t *testing.T) {
+	config := DefaultConfig()
+	config.MapPath = "data/maps/missing.txt"
+	var notFound *MapNotFoundError
+	if _, err := InitGame(config); !errors.As(err, &notFound) || notFound.Path != config.MapPath {
+		t.Errorf("missing map: got error %v, expected a MapNotFoundError", err)
+	}
+
+	tests := []struct {
+		name     string
+		gameMap  string
+		expected error
+	}{
+		{"empty map", "", ErrEmptyMap},
+		{"empty layers", "[collision]\n[entities]\n", ErrEmptyMap},
+		{"unknown layer", "[collision]\nss\n[sky]\n", &MapSyntaxError{3, `unknown layer "sky"`}},
+		{"layer defined twice", "[collision]\nss\n[collision]\nss\n", &MapSyntaxError{3, `layer "collision" is defined twice`}},
+		{"no ground", "[collision]\ns s\ns s\n[entities]\n p\n", &NoGroundError{Position{1.5, 0.5}}},
+	}
+	for _, test := range tests {
+		_, err := InitGameFromReader(DefaultConfig(), strings.NewReader(test.gameMap))
+		switch expected := test.expected.(type) {
+		case *MapSyntaxError:
+			var got *MapSyntaxError
+			if !errors.As(err, &got) || *got != *expected {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		case *NoGroundError:
+			var got *NoGroundError
+			if !errors.As(err, &got) || *got != *expected {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		default:
+			if !errors.Is(err, expected) {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		}
+	}
+}
+
+// Map with an unknown block in the collision layer and an unknown entity
+const unknownRunesMap string = `[collision]
+s  s
+s? s
+ssss
+[entities]
+ p
+  s
+`
+
+func TestStrictValidation(t *testing.T) {
+	config := DefaultConfig()
+	config.Strict = true
+	_, err := InitGameFromReader(config, strings.NewReader(unknownRunesMap))
+
+	var validation *MapValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("got error %v, expected a MapValidationError", err)
+	}
+	expected := []UnknownRuneError{{'?', CollisionLayer, 3, 2}, {'s', EntityLayer, 7, 3}}
+	if len(validation.Unknown) != len(expected) {
+		t.Fatalf("got %v, expected %v", validation.Unknown, expected)
+	}
+	for i := range expected {
+		if validation.Unknown[i] != expected[i] {
+			t.Errorf("got %v, expected %v", validation.Unknown[i], expected[i])
+		}
+	}
+
+	var unknown *UnknownRuneError
+	if !errors.As(err, &unknown) || *unknown != expected[0] {
+		t.Errorf("errors.As gives %v, expected the first unknown rune %v", unknown, expected[0])
+	}
+}
+
+func TestLenientValidation(t *testing.T) {
+	g := newTestGame(t, unknownRunesMap)
+	if len(g.MapWarnings) != 2 {
+		t.Fatalf("got warnings %v, expected 2 unknown runes", g.MapWarnings)
+	}
+	if g.GameMap[1][1] != ' ' || g.entityMap[2][1] != ' ' {
+		t.Error("unknown runes are not replaced by air")
+	}
+	if g.GameMap[0][1] != 's' {
+		t.Error("known blocks are replaced")
+	}
+}
+
+func TestSpawnInMapWithoutLayers(t *testing.T) {
+	g := newTestGame(t, "s   s\ns p s\nsssss\n")
+	if g.Spawn != (Position{2.5, 1.5}) || g.Player.Position != g.Spawn {
+		t.Errorf("spawn is %v and player is at %v, expected (2.5, 1.5)", g.Spawn, g.Player.Position)
+	}
+	if g.GameMap[2][1] != ' ' {
+		t.Error("spawn point is left as a block in the map")
+	}
+
+	g = newTestGame(t, "[collision]\ns   s\nsssss\n[entities]\n p\n")
+	if g.Spawn != (Position{1.5, 0.5}) {
+		t.Errorf("spawn is %v, expected (1.5, 0.5) from the entity layer", g.Spawn)
+	}
+}
@@ -1,6 +0,0 @@
-package game
-
-type Object struct {
-	name        string
-	description string
-}
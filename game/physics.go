@@ -0,0 +1,110 @@
+package game
+
+// Physics is simulated by fixed steps, whatever the rate the game is updated at,
+// so that walking and jumping feel the same at any tick rate.
+const FixedDelta float64 = 1.0 / 120.0 // Duration of a physics step (seconds)
+const maxFrameDelta float64 = 0.25     // Longest frame simulated, to not freeze after a hiccup (seconds)
+
+const gravity float64 = 36.0              // Vertical acceleration (blocks per second²)
+const timeTillLongJump float64 = 8.0 / 60 // Time until long press turns into long jump (seconds)
+const timeCantJumpInAir float64 = 0.1     // Time player can still jump after falling (seconds)
+const timeDropThrough float64 = 0.25      // Time player falls through platforms after dropping (seconds)
+
+// Advances the game by dt seconds depending on the player's input,
+// running as many physics steps as needed
+func (g *Game) Update(input InputState, dt float64) {
+	if dt > maxFrameDelta {
+		dt = maxFrameDelta
+	}
+	g.accumulator += dt
+	for g.accumulator >= FixedDelta {
+		g.Step(input)
+		g.accumulator -= FixedDelta
+	}
+}
+
+// Advances the game by one physics step (FixedDelta) depending on the player's input,
+// nothing moves anymore once the level is finished
+func (g *Game) Step(input InputState) {
+	if g.Finished {
+		return
+	}
+	g.Time += FixedDelta
+	g.stepJumpOrFall()
+	g.stepInput(input)
+	g.stepInteract(input)
+	g.stepAttack(input)
+	g.stepBuild(input)
+	g.stepEntities()
+	g.stepHealth()
+	g.stepGoals()
+	g.stepTimeLimit()
+}
+
+// Manages jump and fall of player
+func (g *Game) stepJumpOrFall() {
+	// Move vertically the player depending on its vertical velocity
+	g.Player.VerticalVelocity += gravity * FixedDelta
+	if g.Move(0.0, g.Player.VerticalVelocity*FixedDelta) {
+		if g.timeInAir >= timeCantJumpInAir {
+			g.timeInAir = 0
+			g.Player.TouchingGround = false
+		} else {
+			g.timeInAir += FixedDelta
+		}
+	}
+}
+
+// Manages input for controlling player
+func (g *Game) stepInput(input InputState) {
+	// Walking left
+	if input.Left {
+		g.Player.Direction = 'l'
+		g.Player.Walking = g.Move(-g.Player.Speed*FixedDelta, 0)
+	}
+
+	// Walking right
+	if input.Right {
+		g.Player.Direction = 'r'
+		g.Player.Walking = g.Move(g.Player.Speed*FixedDelta, 0)
+	}
+
+	if !input.Left && !input.Right && g.Player.Walking {
+		g.Player.Walking = false
+	}
+
+	if g.Player.DropTime > 0 {
+		g.Player.DropTime -= FixedDelta
+	}
+
+	// Dropping through a platform
+	if input.Down && input.Jump && g.Jump == 0 && g.onPlatform() {
+		g.Jump = timeTillLongJump // Holding jump must not jump once below the platform
+		g.Player.DropTime = timeDropThrough
+		g.Player.TouchingGround = false
+		return
+	}
+
+	// Jumping
+	if input.Jump {
+		if g.Player.TouchingGround {
+			g.Jump = FixedDelta
+			g.Player.TouchingGround = false
+			g.Player.VerticalVelocity = g.Player.VelocityShortJump
+			g.Move(0.0, -0.01)
+		} else if g.Jump > 0 && g.Jump < timeTillLongJump {
+			g.Jump += FixedDelta
+			if g.Jump >= timeTillLongJump {
+				g.Player.VerticalVelocity += g.Player.VelocityDiffLongJump
+			}
+		}
+	} else {
+		g.Jump = 0
+	}
+}
+
+// Checks if the player is standing on a platform
+func (g *Game) onPlatform() bool {
+	hit, ok := g.Sweep(g.Player.Box(), 0, 2*skin, true)
+	return ok && hit.Block.Solidity == Platform
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+const stepsPerSecond int = int(1 / FixedDelta)
+
+// Checks that the player stands still at a height (position of its center)
+func checkStanding(t *testing.T, g *Game, y float64) {
+	t.Helper()
+	if !g.Player.TouchingGround || math.Abs(g.Player.Position.Y-y) > 0.01 {
+		t.Errorf("player at y=%.3f (touching ground: %v), expected to stand at y=%.1f",
+			g.Player.Position.Y, g.Player.TouchingGround, y)
+	}
+}
+
+func TestPlatformJumpUpThrough(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s    s
+s    s
+s _  s
+s    s
+ssssss
+[entities]
+
+
+
+  p
+`)
+	g.steps(stepsPerSecond/2, InputState{})
+	checkStanding(t, &g, 3.5)
+
+	// A long jump goes through the platform from below, then lands on it
+	highest := g.Player.Box().MinY
+	for i := 0; i < stepsPerSecond*2; i++ {
+		g.Step(InputState{Jump: i < stepsPerSecond/4})
+		highest = math.Min(highest, g.Player.Box().MinY)
+	}
+	if highest >= 2 {
+		t.Errorf("player's head went up to y=%.3f, expected to cross the platform", highest)
+	}
+	checkStanding(t, &g, 1.5)
+}
+
+func TestPlatformWalkThrough(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s    s
+s    s
+s  _ s
+ssssss
+[entities]
+
+
+ p
+`)
+	g.steps(stepsPerSecond/2, InputState{})
+	g.steps(stepsPerSecond, InputState{Right: true})
+	if g.Player.Position.X < 4 {
+		t.Errorf("player stopped at x=%.3f, expected to walk through the platform", g.Player.Position.X)
+	}
+	checkStanding(t, &g, 2.5)
+}
+
+func TestPlatformLanding(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s    s
+s    s
+s __ s
+s    s
+ssssss
+[entities]
+  p
+`)
+	g.steps(stepsPerSecond, InputState{})
+	checkStanding(t, &g, 1.5)
+	if !g.onPlatform() {
+		t.Error("player is not on the platform")
+	}
+
+	// Jumping without down does not drop through it
+	g.steps(stepsPerSecond*2, InputState{Jump: true})
+	g.steps(stepsPerSecond, InputState{})
+	checkStanding(t, &g, 1.5)
+}
+
+func TestPlatformDropThrough(t *testing.T) {
+	g := newTestGame(t, `[collision]
+s    s
+s    s
+s __ s
+s    s
+ssssss
+[entities]
+  p
+`)
+	g.steps(stepsPerSecond, InputState{})
+	checkStanding(t, &g, 1.5)
+
+	// Holding both keys must not jump once below the platform
+	g.steps(stepsPerSecond/10, InputState{Down: true, Jump: true})
+	if g.Player.Position.Y <= 1.5 {
+		t.Errorf("player at y=%.3f, expected to fall below the platform", g.Player.Position.Y)
+	}
+	g.steps(stepsPerSecond/2, InputState{})
+	checkStanding(t, &g, 3.5)
+}
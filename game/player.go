@@ -5,46 +5,58 @@ type Player struct {
 	Position Position
 	EatBox   [4][2]float64 // 4 points in rectangle around player
 
-	// Speed and velocities for moving
+	// Speed and velocities for moving (in blocks per second)
 	Speed                float64 // Speed when walking
-	VelocityShortJump    float64 // Vertical velocity hen short jump
+	VelocityShortJump    float64 // Vertical velocity when short jump
 	VelocityDiffLongJump float64 // Add of vertical velocity for long jump
 
 	// State
 	Direction        rune    // l or r
 	TouchingGround   bool    // True if player is walking, false if falling or jumping
-	VerticalVelocity float64 // Vertical velocity on air (gravity falling, or gravity jumping)
+	VerticalVelocity float64 // Vertical velocity on air in blocks per second (gravity falling, or gravity jumping)
 	Walking          bool    // Animate player when walking
+	DropTime         float64 // Time left to fall through platforms (seconds)
+
+	// Health
+	Health       int     // Hearts left
+	MaxHealth    int     // Hearts when (re)spawning
+	Lives        int     // Respawns left before restarting the level
+	Invulnerable float64 // Time left without taking damage after a hit (seconds)
+
+	// Attack
+	Weapon         ItemID  // Item of the inventory used to attack, none if empty
+	AttackCooldown float64 // Time left before attacking again (seconds)
+
+	// Building
+	Block ItemID // Item of the inventory placed as a block, none if empty
 
 	// Stuff
-	Gold      int      // Gold earned
-	Keys      int      // Number of keys owned
-	Inventory []Object // List of object
+	Gold      int         // Gold earned
+	Inventory []ItemStack // Items carried, by slot
 }
 
 // Initialize a new player with default settings
-func initPlayer(xPlayerFixed int) Player {
+func initPlayer() Player {
 	return Player{
-		Position{float64(xPlayerFixed) + 6.5, 2},
-		[4][2]float64{
+		Position: Position{6.5, 2},
+		EatBox: [4][2]float64{
 			{-0.3, -0.4},
 			{0.3, -0.4},
 			{0.3, 0.5},
 			{-0.3, 0.5},
 		},
 
-		0.09,
-		-17.0,
-		-5.9,
+		Speed:                5.4,
+		VelocityShortJump:    -10.2,
+		VelocityDiffLongJump: -3.54,
 
-		'r',
-		false,
-		0.0,
-		false,
+		Direction: 'r',
 
-		0,
-		0,
-		[]Object{},
+		Health:    startHealth,
+		MaxHealth: startHealth,
+		Lives:     startLives,
+
+		Inventory: []ItemStack{},
 	}
 }
 
@@ -54,23 +66,29 @@ func (p *Player) Move(x, y float64) {
 	p.Position.Y += y
 }
 
+// Returns the eat-box of the player as a box
+func (p *Player) Box() AABB {
+	return AABB{
+		p.Position.X + p.EatBox[0][0],
+		p.Position.Y + p.EatBox[0][1],
+		p.Position.X + p.EatBox[2][0],
+		p.Position.Y + p.EatBox[2][1],
+	}
+}
+
 // Collects n golds
 func (p *Player) CollectGold(number int) {
 	p.Gold += number
 }
 
-// Return true if object can be bought, if yes, decreases Gold by cost
-func (p *Player) Buy(cost int) (canBuy bool) {
-	if p.Gold-cost >= 0 {
-		p.Gold = -cost
-		canBuy = true
+// Decreases Gold by cost if the player has enough, gold never goes negative
+func (p *Player) Buy(cost int) error {
+	if cost < 0 {
+		return &InvalidPriceError{cost}
 	}
-	return
-}
-
-func (p *Player) AddInventory(short rune) {
-	switch short {
-	case 'k':
-		p.Inventory = append(p.Inventory, Object{"Key", "Into the unknown."})
+	if p.Gold < cost {
+		return ErrInsufficientFunds
 	}
+	p.Gold -= cost
+	return nil
 }
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const recordingVersion int = 4 // Version of the recording format, increased when it changes
+
+// Returned when a recording cannot be replayed on the current files
+type RecordingMismatchError struct {
+	Message string
+}
+
+func (e *RecordingMismatchError) Error() string {
+	return fmt.Sprintf("recording cannot be replayed: %s", e.Message)
+}
+
+// Returned when the end of a replay differs from the end of the recording
+type ReplayMismatchError struct {
+	Expected RecordedStats
+	Got      RecordedStats
+}
+
+func (e *ReplayMismatchError) Error() string {
+	return fmt.Sprintf("replay ended with %+v, recorded %+v", e.Got, e.Expected)
+}
+
+// Input of every frame of a game, with what is needed to replay it exactly
+type Recording struct {
+	Version    int           `json:"version"`
+	MapPath    string        `json:"map"`
+	MapHash    string        `json:"mapHash"`
+	BlocksPath string        `json:"blocks"`
+	BlocksHash string        `json:"blocksHash"`
+	TimeLimit  float64       `json:"timeLimit"` // Time limit of the level when recorded (seconds), no limit if 0
+	Start      SaveState     `json:"start"`     // State of the game before the first frame
+	Tick       uint64        `json:"tick"`      // Animation tick of the window before the first frame
+	TickFrame  uint8         `json:"tickFrame"` // Animation frame of the window before the first frame
+	Delta      float64       `json:"delta"`     // Duration of each frame (seconds)
+	Inputs     []uint8       `json:"inputs"`    // Input of each frame, packed in bits
+	End        RecordedStats `json:"end"`       // Stats after the last frame
+}
+
+// Stats compared at the end of a replay
+type RecordedStats struct {
+	Position Position `json:"position"`
+	Gold     int      `json:"gold"`
+	Keys     int      `json:"keys"`
+}
+
+// Starts recording the game from its current state, updated every delta seconds
+func (g *Game) StartRecording(tick uint64, tickFrame uint8, delta float64) *Recording {
+	return &Recording{
+		Version:    recordingVersion,
+		MapPath:    g.config.MapPath,
+		MapHash:    g.mapHash,
+		BlocksPath: g.config.BlocksPath,
+		BlocksHash: g.blocksHash,
+		TimeLimit:  g.TimeLimit,
+		Start:      g.SaveState(),
+		Tick:       tick,
+		TickFrame:  tickFrame,
+		Delta:      delta,
+		Inputs:     []uint8{},
+		End:        g.stats(),
+	}
+}
+
+// Adds the input of a frame to the recording
+func (r *Recording) Add(input InputState) {
+	r.Inputs = append(r.Inputs, input.bits())
+}
+
+// Number of recorded frames
+func (r *Recording) Len() int {
+	return len(r.Inputs)
+}
+
+// Input of a recorded frame
+func (r *Recording) Input(frame int) InputState {
+	return inputFromBits(r.Inputs[frame])
+}
+
+// Records the stats of the game after the last frame
+func (r *Recording) Finish(g *Game) {
+	r.End = g.stats()
+}
+
+// Checks that the game ends the same way as the recording
+func (r *Recording) Check(g *Game) error {
+	if g.stats() != r.End {
+		return &ReplayMismatchError{r.End, g.stats()}
+	}
+	return nil
+}
+
+// Writes the recording in a file
+func (r *Recording) Save(path string) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// Reads a recording from a file
+func LoadRecording(path string) (*Recording, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Recording
+	if err := json.Unmarshal(content, &r); err != nil {
+		return nil, fmt.Errorf("cannot read recording %s: %w", path, err)
+	}
+	if r.Version != recordingVersion {
+		return nil, &RecordingMismatchError{fmt.Sprintf("version %d is not supported (expected %d)", r.Version, recordingVersion)}
+	}
+	return &r, nil
+}
+
+// Initializes a game in the state where the recording started,
+// with the map, blocks and time limit of the recording
+// (whatever the levels manifest of the config says)
+func (r *Recording) InitGame(config Config) (Game, error) {
+	config.MapPath = r.MapPath
+	config.BlocksPath = r.BlocksPath
+	g, err := InitGame(config)
+	if err != nil {
+		return g, err
+	}
+
+	if g.mapHash != r.MapHash {
+		return g, &RecordingMismatchError{fmt.Sprintf("map %s changed since it was recorded", r.MapPath)}
+	}
+	if g.blocksHash != r.BlocksHash {
+		return g, &RecordingMismatchError{fmt.Sprintf("blocks %s changed since they were recorded", r.BlocksPath)}
+	}
+	g.TimeLimit = r.TimeLimit
+	return g, g.LoadState(r.Start)
+}
+
+// Replays the whole recording without window and checks its end
+func (r *Recording) Replay(config Config) (Game, error) {
+	g, err := r.InitGame(config)
+	if err != nil {
+		return g, err
+	}
+	for frame := 0; frame < r.Len(); frame++ {
+		g.Update(r.Input(frame), r.Delta)
+	}
+	return g, r.Check(&g)
+}
+
+// Stats of the game compared at the end of a replay
+func (g *Game) stats() RecordedStats {
+	return RecordedStats{g.Player.Position, g.Player.Gold, g.CountItem(KeyItem)}
+}
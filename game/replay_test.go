@@ -0,0 +1,35 @@
+package game
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Replays every recording of game/testdata, each one must end as it was recorded
+func TestRecordedReplays(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("game", "testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no recording in game/testdata")
+	}
+
+	for _, path := range paths {
+		r, err := LoadRecording(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if _, err := r.Replay(DefaultConfig()); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+
+		// The time limit is taken from the recording, not from the levels manifest
+		config := DefaultConfig()
+		config.LevelsPath = ""
+		if _, err := r.Replay(config); err != nil {
+			t.Errorf("%s without levels manifest: %v", path, err)
+		}
+	}
+}
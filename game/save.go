@@ -0,0 +1,288 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const DefaultSaveDir string = "saves"
+
+const saveVersion int = 9 // Version of the save format, increased when it changes
+const QuickSaveSlot int = 0
+
+// Returned when there is no save in a slot
+type SaveNotFoundError struct {
+	Slot int
+}
+
+func (e *SaveNotFoundError) Error() string {
+	return fmt.Sprintf("no save in slot %d", e.Slot)
+}
+
+// Returned when a save cannot be loaded in the current game
+type SaveMismatchError struct {
+	Message string
+}
+
+func (e *SaveMismatchError) Error() string {
+	return fmt.Sprintf("save cannot be loaded: %s", e.Message)
+}
+
+// State of the game written in a save file
+type SaveState struct {
+	Version  int           `json:"version"`
+	MapPath  string        `json:"map"`
+	Player   SavedPlayer   `json:"player"`
+	Start    SavedPlayer   `json:"start"`   // Stats of the player when the level started, given back when it starts again
+	Spawn    Position      `json:"spawn"`   // Last checkpoint
+	Time     float64       `json:"time"`    // Time spent in the level (seconds)
+	Seed     uint64        `json:"seed"`    // State of the random generator (loot of chests)
+	Changes  []MapChange   `json:"changes"` // Only the blocks that differ from the original map
+	Entities []SavedEntity `json:"entities"`
+}
+
+// Saved stats of the player
+type SavedPlayer struct {
+	Position  Position    `json:"position"`
+	Direction string      `json:"direction"`
+	Gold      int         `json:"gold"`
+	Health    int         `json:"health"`
+	Lives     int         `json:"lives"`
+	Inventory []SavedItem `json:"inventory"`
+	Weapon    string      `json:"weapon"`
+	Block     string      `json:"block"`
+}
+
+// Saved slot of the inventory
+type SavedItem struct {
+	Item  string `json:"item"`
+	Count int    `json:"count"`
+}
+
+// Saved entity moving in the map
+type SavedEntity struct {
+	Short     string   `json:"block"`
+	Position  Position `json:"position"`
+	VelocityX float64  `json:"velocityX"`
+	Health    int      `json:"health"`
+}
+
+// Block of the game map that differs from the original map
+type MapChange struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	Short string `json:"block"`
+}
+
+// Returns the current state of the game to save
+func (g *Game) SaveState() SaveState {
+	state := SaveState{
+		Version:  saveVersion,
+		MapPath:  g.config.MapPath,
+		Player:   savePlayer(g.Player),
+		Start:    savePlayer(g.startPlayer),
+		Spawn:    g.Spawn,
+		Time:     g.Time,
+		Seed:     g.seed,
+		Changes:  []MapChange{},
+		Entities: []SavedEntity{},
+	}
+
+	for x := range g.GameMap {
+		for y, c := range g.GameMap[x] {
+			if c != g.originalMap[x][y] {
+				state.Changes = append(state.Changes, MapChange{x, y, string(c)})
+			}
+		}
+	}
+	for _, e := range g.Entities {
+		state.Entities = append(state.Entities, SavedEntity{string(e.Short), e.Position, e.VelocityX, e.Health})
+	}
+	return state
+}
+
+// Returns the stats of a player to save
+func savePlayer(p Player) SavedPlayer {
+	saved := SavedPlayer{
+		Position:  p.Position,
+		Direction: string(p.Direction),
+		Gold:      p.Gold,
+		Health:    p.Health,
+		Lives:     p.Lives,
+		Inventory: []SavedItem{},
+		Weapon:    string(p.Weapon),
+		Block:     string(p.Block),
+	}
+	for _, s := range p.Inventory {
+		saved.Inventory = append(saved.Inventory, SavedItem{string(s.Item), s.Count})
+	}
+	return saved
+}
+
+// Checks the items of saved stats against the items of the game,
+// returns the inventory, weapon and block held
+func (g *Game) loadSavedItems(p SavedPlayer) (inventory []ItemStack, weapon, block ItemID, err error) {
+	inventory = []ItemStack{}
+	for _, s := range p.Inventory {
+		item, ok := g.Items[ItemID(s.Item)]
+		if !ok || s.Count <= 0 || s.Count > item.StackLimit {
+			return nil, "", "", &SaveMismatchError{fmt.Sprintf("invalid inventory slot of %d %q", s.Count, s.Item)}
+		}
+		inventory = append(inventory, ItemStack{item.ID, s.Count})
+	}
+	if len(inventory) > inventorySize {
+		return nil, "", "", &SaveMismatchError{fmt.Sprintf("%d inventory slots (at most %d)", len(inventory), inventorySize)}
+	}
+	weapon = ItemID(p.Weapon)
+	if item, ok := g.Items[weapon]; weapon != "" && (!ok || item.Use != Equip) {
+		return nil, "", "", &SaveMismatchError{fmt.Sprintf("unknown weapon %q", weapon)}
+	}
+	block = ItemID(p.Block)
+	if item, ok := g.Items[block]; block != "" && (!ok || item.Use != Place) {
+		return nil, "", "", &SaveMismatchError{fmt.Sprintf("unknown held block %q", block)}
+	}
+	return inventory, weapon, block, nil
+}
+
+// Restores a saved state on the original map
+func (g *Game) LoadState(state SaveState) error {
+	if state.Version != saveVersion {
+		return &SaveMismatchError{fmt.Sprintf("version %d is not supported (expected %d)", state.Version, saveVersion)}
+	}
+	if filepath.Clean(state.MapPath) != filepath.Clean(g.config.MapPath) {
+		return &SaveMismatchError{fmt.Sprintf("saved on map %s, not %s", state.MapPath, g.config.MapPath)}
+	}
+
+	// Check all changes before modifying the game
+	gameMap := copyLayer(g.originalMap)
+	for _, c := range state.Changes {
+		short := []rune(c.Short)
+		if g.outOfMap([]int{c.X}, []int{c.Y}) {
+			return &SaveMismatchError{fmt.Sprintf("block (%d, %d) is out of the map", c.X, c.Y)}
+		}
+		if len(short) != 1 || !g.knownRune(CollisionLayer, short[0]) {
+			return &SaveMismatchError{fmt.Sprintf("unknown block %q at (%d, %d)", c.Short, c.X, c.Y)}
+		}
+		gameMap[c.X][c.Y] = short[0]
+	}
+	inventory, weapon, block, err := g.loadSavedItems(state.Player)
+	if err != nil {
+		return err
+	}
+	startInventory, startWeapon, startBlock, err := g.loadSavedItems(state.Start)
+	if err != nil {
+		return err
+	}
+	entities := []Entity{}
+	for _, e := range state.Entities {
+		short := []rune(e.Short)
+		if len(short) != 1 || g.AllBlocks[short[0]].Entity == "" {
+			return &SaveMismatchError{fmt.Sprintf("unknown entity %q", e.Short)}
+		}
+		entity := newEntity(g.AllBlocks[short[0]], 0, 0)
+		entity.Position = e.Position
+		entity.VelocityX = e.VelocityX
+		if e.Health > 0 {
+			entity.Health = e.Health
+		}
+		entities = append(entities, entity)
+	}
+
+	g.GameMap = gameMap
+	g.Entities = entities
+	g.Player.Position = state.Player.Position
+	g.Player.Direction = 'r'
+	if state.Player.Direction == "l" {
+		g.Player.Direction = 'l'
+	}
+	g.Player.Gold = state.Player.Gold
+	g.Player.Health = state.Player.Health
+	g.Player.Lives = state.Player.Lives
+	g.Spawn = state.Spawn
+	g.Time = state.Time
+	g.seed = state.Seed
+	g.Player.Inventory = inventory
+	g.Player.Weapon = weapon
+	g.Player.Block = block
+	g.startPlayer.Gold = state.Start.Gold
+	g.startPlayer.Inventory = startInventory
+	g.startPlayer.Weapon = startWeapon
+	g.startPlayer.Block = startBlock
+
+	// Player starts again at rest
+	g.Player.VerticalVelocity = 0
+	g.Player.TouchingGround = false
+	g.Player.Walking = false
+	g.Player.DropTime = 0
+	g.Player.Invulnerable = 0
+	g.Player.AttackCooldown = 0
+	g.Swing = Swing{}
+	g.Projectiles = []Projectile{}
+	g.Mining = Mining{}
+	g.Jump = 0
+	g.timeInAir = 0
+	g.accumulator = 0
+	g.Finished = false
+	g.interactHeld = false
+	g.attackHeld = false
+	g.placeHeld = false
+	g.events = []Event{}
+	return nil
+}
+
+// Writes the state of the game in a save slot
+func (g *Game) Save(slot int) error {
+	content, err := json.MarshalIndent(g.SaveState(), "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(g.config.SaveDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(g.savePath(slot), content, 0644)
+}
+
+// Restores the state of the game from a save slot, switching level if it was saved in another one
+func (g *Game) Load(slot int) error {
+	content, err := os.ReadFile(g.savePath(slot))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &SaveNotFoundError{slot}
+		}
+		return err
+	}
+
+	var state SaveState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return fmt.Errorf("cannot read save of slot %d: %w", slot, err)
+	}
+
+	// Saved in another level of the world, which is loaded first
+	if filepath.Clean(state.MapPath) != filepath.Clean(g.config.MapPath) {
+		if index := g.World.find(state.MapPath); index >= 0 {
+			next := *g
+			if err := next.LoadLevel(index, false); err != nil {
+				return err
+			}
+			if err := next.LoadState(state); err != nil {
+				return err
+			}
+			*g = next
+			return nil
+		}
+	}
+	return g.LoadState(state)
+}
+
+// Path of the file of a save slot
+func (g *Game) savePath(slot int) string {
+	name := "slot" + strconv.Itoa(slot) + ".json"
+	if slot == QuickSaveSlot {
+		name = "quicksave.json"
+	}
+	return filepath.Join(g.config.SaveDir, name)
+}
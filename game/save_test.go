@@ -0,0 +1,41 @@
+package game
+
+import "testing"
+
+func TestLoadKeepsTheStatsOfTheLevelStart(t *testing.T) {
+	g, err := InitGame(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.config.SaveDir = t.TempDir()
+	g.AddItem(KeyItem, 2)
+	g.Player.Gold = 7
+	if err := g.NextLevel(true); err != nil {
+		t.Fatal(err)
+	}
+	g.AddItem(KeyItem, 3)
+	g.Player.Gold += 5
+	if err := g.Save(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Loaded from the first level, the save switches to the second one
+	loaded, err := InitGame(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.config.SaveDir = g.config.SaveDir
+	if err := loaded.Load(1); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Level != 1 || loaded.CountItem(KeyItem) != 5 || loaded.Player.Gold != 12 {
+		t.Fatalf("loaded level %d with %d keys and %d golds, expected level 1 with 5 and 12",
+			loaded.Level, loaded.CountItem(KeyItem), loaded.Player.Gold)
+	}
+
+	loaded.Player.Lives = 1
+	loaded.killPlayer()
+	if keys := loaded.CountItem(KeyItem); keys != 2 || loaded.Player.Gold != 7 {
+		t.Errorf("level restarted with %d keys and %d golds, expected the 2 and 7 of its start", keys, loaded.Player.Gold)
+	}
+}
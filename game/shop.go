@@ -0,0 +1,77 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInsufficientFunds = errors.New("not enough gold")
+var ErrInventoryFull = errors.New("no room in the inventory")
+
+// Returned when something is sold for a negative price
+type InvalidPriceError struct {
+	Price int
+}
+
+func (e *InvalidPriceError) Error() string {
+	return fmt.Sprintf("invalid price %d", e.Price)
+}
+
+// Returned when buying an offer that a shop does not have
+type UnknownOfferError struct {
+	Shop  string
+	Offer int
+}
+
+func (e *UnknownOfferError) Error() string {
+	return fmt.Sprintf("no offer #%d in shop %q", e.Offer+1, e.Shop)
+}
+
+// Item sold in a shop, in the blocks definitions file
+type ShopOffer struct {
+	Item  ItemID `json:"item"`
+	Count int    `json:"count"` // Number of items sold together, defaults to 1
+	Price int    `json:"price"` // Golds paid
+}
+
+// Returns the shop the player stands on or is next to, empty if none
+func (g *Game) nearShop() (shop string, x, y int) {
+	x = int(g.Player.Position.X)
+	y = int(g.Player.Position.Y)
+	for _, side := range []int{x, x + 1, x - 1} {
+		if !g.outOfMap([]int{side}, []int{y}) {
+			if shop = g.AllBlocks[g.GameMap[side][y]].Shop; shop != "" {
+				return shop, side, y
+			}
+		}
+	}
+	return "", 0, 0
+}
+
+// Buys an offer of a shop, the gold is only paid if the inventory can take the items
+func (g *Game) BuyItem(shop string, offer int) error {
+	offers := g.Shops[shop]
+	if offer < 0 || offer >= len(offers) {
+		return &UnknownOfferError{shop, offer}
+	}
+	o := offers[offer]
+	if g.roomFor(o.Item) < o.Count {
+		return ErrInventoryFull
+	}
+	if err := g.Player.Buy(o.Price); err != nil {
+		return err
+	}
+	g.AddItem(o.Item, o.Count)
+	return nil
+}
+
+// Returns how many items of a type the inventory can still take
+func (g *Game) roomFor(id ItemID) (room int) {
+	limit := g.Items[id].StackLimit
+	for _, s := range g.Player.Inventory {
+		if s.Item == id && s.Count < limit {
+			room += limit - s.Count
+		}
+	}
+	return room + (inventorySize-len(g.Player.Inventory))*limit
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// Map where the player stands on the ground, for tests not depending on blocks
+const flatTestMap string = `[collision]
+s  s
+ssss
+[entities]
+ p
+`
+
+func TestBuyItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		gold      int
+		inventory []ItemStack
+		offer     int
+		expected  error
+		goldLeft  int
+		slots     []ItemStack // Inventory after buying
+	}{
+		{"not enough gold", 7, nil, 0, ErrInsufficientFunds, 7, nil},
+		{"exact price", 8, nil, 0, nil, 0, []ItemStack{{"potion", 1}}},
+		{"negative price", 10, nil, 2, &InvalidPriceError{-1}, 10, nil},
+		{"full inventory", 20, fullOf("sword", inventorySize), 0, ErrInventoryFull, 20, fullOf("sword", inventorySize)},
+		{"unknown offer", 20, nil, 4, &UnknownOfferError{"test", 4}, 20, nil},
+		{"negative offer", 20, nil, -1, &UnknownOfferError{"test", -1}, 20, nil},
+		{"stack limit", 20, []ItemStack{{"potion", 2}}, 1, nil, 0, []ItemStack{{"potion", 3}, {"potion", 2}}},
+		{"stack limit without room", 20, append([]ItemStack{{"potion", 2}}, fullOf("sword", inventorySize-1)...), 1,
+			ErrInventoryFull, 20, append([]ItemStack{{"potion", 2}}, fullOf("sword", inventorySize-1)...)},
+	}
+
+	for _, test := range tests {
+		g := newTestGame(t, flatTestMap)
+		g.Shops["test"] = []ShopOffer{{"potion", 1, 8}, {"potion", 3, 20}, {"key", 1, -1}}
+		g.Player.Gold = test.gold
+		g.Player.Inventory = append([]ItemStack{}, test.inventory...)
+
+		err := g.BuyItem("test", test.offer)
+		switch expected := test.expected.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.name, err)
+			}
+		case *InvalidPriceError:
+			var got *InvalidPriceError
+			if !errors.As(err, &got) || *got != *expected {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		case *UnknownOfferError:
+			var got *UnknownOfferError
+			if !errors.As(err, &got) || *got != *expected {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		default:
+			if !errors.Is(err, expected) {
+				t.Errorf("%s: got error %v, expected %v", test.name, err, expected)
+			}
+		}
+
+		if g.Player.Gold != test.goldLeft {
+			t.Errorf("%s: %d golds left, expected %d", test.name, g.Player.Gold, test.goldLeft)
+		}
+		if !sameSlots(g.Player.Inventory, test.slots) {
+			t.Errorf("%s: inventory is %v, expected %v", test.name, g.Player.Inventory, test.slots)
+		}
+	}
+}
+
+func TestPlayerBuy(t *testing.T) {
+	p := initPlayer()
+	p.Gold = 5
+	if err := p.Buy(6); !errors.Is(err, ErrInsufficientFunds) || p.Gold != 5 {
+		t.Errorf("buying over the gold: got %v with %d golds left", err, p.Gold)
+	}
+	if err := p.Buy(5); err != nil || p.Gold != 0 {
+		t.Errorf("buying with the exact gold: got %v with %d golds left", err, p.Gold)
+	}
+	var invalid *InvalidPriceError
+	if err := p.Buy(-3); !errors.As(err, &invalid) || invalid.Price != -3 || p.Gold != 0 {
+		t.Errorf("buying for a negative price: got %v with %d golds left", err, p.Gold)
+	}
+}
+
+// Returns slots holding one item each
+func fullOf(id ItemID, slots int) (inventory []ItemStack) {
+	for i := 0; i < slots; i++ {
+		inventory = append(inventory, ItemStack{id, 1})
+	}
+	return
+}
+
+// Checks if two inventories hold the same slots (nil and empty are the same)
+func sameSlots(a, b []ItemStack) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,397 @@
+package game
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Flags stored in the highest bits of Tiled's global tile IDs (flipping and rotation)
+const tiledFlipFlags uint32 = 0xF0000000
+
+// Property of a tile or an object giving the block's short identifier
+const tiledShortProperty string = "short"
+
+// Object type (or class) placing the player's spawn point
+const tiledSpawnType string = "spawn"
+
+// Orthogonal map made with the Tiled editor (fields of the .tmj format)
+type tiledMap struct {
+	Orientation string         `json:"orientation"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Infinite    bool           `json:"infinite"`
+	Tilesets    []tiledTileset `json:"tilesets"`
+	Layers      []tiledLayer   `json:"layers"`
+}
+
+type tiledTileset struct {
+	FirstGID int         `json:"firstgid"`
+	Name     string      `json:"name"`
+	Source   string      `json:"source"` // External tilesets are not supported
+	Tiles    []tiledTile `json:"tiles"`
+}
+
+type tiledTile struct {
+	ID         int             `json:"id"`
+	Properties []tiledProperty `json:"properties"`
+}
+
+type tiledProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type tiledLayer struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"` // tilelayer, objectgroup or group
+	Width    int             `json:"width"`
+	Height   int             `json:"height"`
+	Encoding string          `json:"encoding"`
+	Data     json.RawMessage `json:"data"` // Array of global tile IDs, read in tiles
+	Objects  []tiledObject   `json:"objects"`
+	Layers   []tiledLayer    `json:"layers"` // Layers of a group
+
+	tiles []uint32
+}
+
+type tiledObject struct {
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`  // Before Tiled 1.9
+	Class      string          `json:"class"` // Since Tiled 1.9
+	X          float64         `json:"x"`
+	Y          float64         `json:"y"`
+	Width      float64         `json:"width"`
+	Height     float64         `json:"height"`
+	GID        uint32          `json:"gid"` // Tile objects only
+	Properties []tiledProperty `json:"properties"`
+}
+
+// Orthogonal map made with the Tiled editor (fields of the .tmx format)
+type tmxMap struct {
+	Orientation string       `xml:"orientation,attr"`
+	Width       int          `xml:"width,attr"`
+	Height      int          `xml:"height,attr"`
+	TileWidth   int          `xml:"tilewidth,attr"`
+	TileHeight  int          `xml:"tileheight,attr"`
+	Infinite    int          `xml:"infinite,attr"`
+	Tilesets    []tmxTileset `xml:"tileset"`
+	tmxGroup
+}
+
+type tmxTileset struct {
+	FirstGID int       `xml:"firstgid,attr"`
+	Name     string    `xml:"name,attr"`
+	Source   string    `xml:"source,attr"`
+	Tiles    []tmxTile `xml:"tile"`
+}
+
+type tmxTile struct {
+	ID         int           `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"` // Multiline values
+}
+
+type tmxGroup struct {
+	Name         string           `xml:"name,attr"`
+	Layers       []tmxLayer       `xml:"layer"`
+	ObjectGroups []tmxObjectGroup `xml:"objectgroup"`
+	Groups       []tmxGroup       `xml:"group"`
+}
+
+type tmxLayer struct {
+	Name   string `xml:"name,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Data   struct {
+		Encoding string `xml:"encoding,attr"`
+		Text     string `xml:",chardata"`
+	} `xml:"data"`
+}
+
+type tmxObjectGroup struct {
+	Name    string      `xml:"name,attr"`
+	Objects []tmxObject `xml:"object"`
+}
+
+type tmxObject struct {
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+// Creates the map based on a Tiled JSON map (.tmj)
+func (game *Game) readTiledJSON(r io.Reader, strict bool) error {
+	var m tiledMap
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return fmt.Errorf("cannot read tiled map: %w", err)
+	}
+	if err := readTiledData(m.Layers); err != nil {
+		return err
+	}
+	return game.loadTiled(m, strict)
+}
+
+// Reads the tiles of every tile layer, only uncompressed arrays are supported
+func readTiledData(layers []tiledLayer) error {
+	for i := range layers {
+		l := &layers[i]
+		if l.Type == "group" {
+			if err := readTiledData(l.Layers); err != nil {
+				return err
+			}
+		}
+		if l.Type != "tilelayer" {
+			continue
+		}
+		if l.Encoding != "" && l.Encoding != "csv" {
+			return fmt.Errorf("tiled layer %q: %s encoding is not supported, use CSV", l.Name, l.Encoding)
+		}
+		if err := json.Unmarshal(l.Data, &l.tiles); err != nil {
+			return fmt.Errorf("tiled layer %q: %w", l.Name, err)
+		}
+	}
+	return nil
+}
+
+// Creates the map based on a Tiled XML map (.tmx)
+func (game *Game) readTiledXML(r io.Reader, strict bool) error {
+	var t tmxMap
+	if err := xml.NewDecoder(r).Decode(&t); err != nil {
+		return fmt.Errorf("cannot read tiled map: %w", err)
+	}
+
+	m := tiledMap{t.Orientation, t.Width, t.Height, t.TileWidth, t.TileHeight, t.Infinite != 0,
+		[]tiledTileset{}, []tiledLayer{}}
+	for _, ts := range t.Tilesets {
+		tileset := tiledTileset{ts.FirstGID, ts.Name, ts.Source, []tiledTile{}}
+		for _, tile := range ts.Tiles {
+			tileset.Tiles = append(tileset.Tiles, tiledTile{tile.ID, tmxProperties(tile.Properties)})
+		}
+		m.Tilesets = append(m.Tilesets, tileset)
+	}
+
+	var err error
+	m.Layers, err = tmxLayers(t.tmxGroup)
+	if err != nil {
+		return err
+	}
+	return game.loadTiled(m, strict)
+}
+
+// Converts the layers of a .tmx group (or map) to .tmj layers
+func tmxLayers(group tmxGroup) ([]tiledLayer, error) {
+	layers := []tiledLayer{}
+
+	for _, l := range group.Layers {
+		if l.Data.Encoding != "csv" {
+			return nil, fmt.Errorf("tiled layer %q: %q encoding is not supported, use CSV", l.Name, l.Data.Encoding)
+		}
+		tiles := []uint32{}
+		for _, v := range strings.FieldsFunc(l.Data.Text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+		}) {
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("tiled layer %q: %w", l.Name, err)
+			}
+			tiles = append(tiles, uint32(gid))
+		}
+		layers = append(layers, tiledLayer{Name: l.Name, Type: "tilelayer", Width: l.Width, Height: l.Height, tiles: tiles})
+	}
+
+	for _, og := range group.ObjectGroups {
+		objects := []tiledObject{}
+		for _, o := range og.Objects {
+			objects = append(objects, tiledObject{o.Name, o.Type, o.Class, o.X, o.Y, o.Width, o.Height, o.GID,
+				tmxProperties(o.Properties)})
+		}
+		layers = append(layers, tiledLayer{Name: og.Name, Type: "objectgroup", Objects: objects})
+	}
+
+	for _, g := range group.Groups {
+		sub, err := tmxLayers(g)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, tiledLayer{Name: g.Name, Type: "group", Layers: sub})
+	}
+	return layers, nil
+}
+
+// Converts .tmx properties to .tmj properties
+func tmxProperties(properties []tmxProperty) []tiledProperty {
+	converted := []tiledProperty{}
+	for _, p := range properties {
+		value := p.Value
+		if value == "" {
+			value = p.Text
+		}
+		converted = append(converted, tiledProperty{p.Name, value})
+	}
+	return converted
+}
+
+// Creates the map from a Tiled map, tiles and objects are turned into blocks
+// and entities through their "short" property
+func (game *Game) loadTiled(m tiledMap, strict bool) error {
+	if m.Orientation != "orthogonal" {
+		return fmt.Errorf("tiled map: %q orientation is not supported", m.Orientation)
+	}
+	if m.Infinite {
+		return errors.New("tiled map: infinite maps are not supported")
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		return ErrEmptyMap
+	}
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return fmt.Errorf("tiled map: tiles of %dx%d pixels, expected a positive size", m.TileWidth, m.TileHeight)
+	}
+
+	shorts, err := m.tileShorts()
+	if err != nil {
+		return err
+	}
+
+	// Generate empty layers
+	game.width, game.height = m.Width, m.Height
+	game.layerStart = map[Layer]int{}
+	game.Background = newLayer(nil, game.width, game.height)
+	game.GameMap = newLayer(nil, game.width, game.height)
+	game.Foreground = newLayer(nil, game.width, game.height)
+	game.entityMap = newLayer(nil, game.width, game.height)
+
+	if err := game.fillTiledLayers(m, m.Layers, shorts); err != nil {
+		return err
+	}
+	return game.finishMap(strict)
+}
+
+// Returns the short identifier of every tile that has one, by global tile ID
+func (m tiledMap) tileShorts() (map[uint32]rune, error) {
+	shorts := map[uint32]rune{}
+	for _, ts := range m.Tilesets {
+		if ts.Source != "" {
+			return nil, fmt.Errorf("tiled map: external tileset %q is not supported, embed it in the map", ts.Source)
+		}
+		for _, tile := range ts.Tiles {
+			if short, ok := shortProperty(tile.Properties); ok {
+				shorts[uint32(ts.FirstGID+tile.ID)] = short
+			}
+		}
+	}
+	return shorts, nil
+}
+
+// Fills the layers of the game with the tile layers and object layers of the Tiled map
+func (game *Game) fillTiledLayers(m tiledMap, layers []tiledLayer, shorts map[uint32]rune) error {
+	for _, l := range layers {
+		switch l.Type {
+		case "group":
+			if err := game.fillTiledLayers(m, l.Layers, shorts); err != nil {
+				return err
+			}
+
+		case "tilelayer":
+			layer := Layer(strings.ToLower(l.Name))
+			if !knownLayer(layer) {
+				return fmt.Errorf("tiled layer %q: name must be one of %v", l.Name, AllLayers)
+			}
+			if len(l.tiles) != m.Width*m.Height {
+				return fmt.Errorf("tiled layer %q: %d tiles for a %dx%d map", l.Name, len(l.tiles), m.Width, m.Height)
+			}
+			grid := game.layer(layer)
+			for i, gid := range l.tiles {
+				gid &^= tiledFlipFlags
+				if gid == 0 {
+					continue
+				}
+				short, ok := shorts[gid]
+				if !ok {
+					return fmt.Errorf("tiled layer %q: tile %d has no %q property", l.Name, gid, tiledShortProperty)
+				}
+				grid[i%m.Width][i/m.Width] = short
+			}
+
+		case "objectgroup":
+			for _, o := range l.Objects {
+				short, err := game.objectShort(o, shorts)
+				if err != nil {
+					return fmt.Errorf("tiled layer %q: %w", l.Name, err)
+				}
+
+				// Cell at the center of the object (tile objects are anchored at their bottom)
+				cx := o.X + o.Width/2
+				cy := o.Y + o.Height/2
+				if o.GID != 0 {
+					cy = o.Y - o.Height/2
+				}
+				x, y := int(cx)/m.TileWidth, int(cy)/m.TileHeight
+				if cx < 0 || cy < 0 || game.outOfMap([]int{x}, []int{y}) {
+					return fmt.Errorf("tiled layer %q: object %q is out of the map", l.Name, o.Name)
+				}
+				game.entityMap[x][y] = short
+			}
+		}
+	}
+	return nil
+}
+
+// Finds the entity placed by an object: its "short" property, the spawn type,
+// the name of a block as type, or its tile
+func (game *Game) objectShort(o tiledObject, shorts map[uint32]rune) (rune, error) {
+	if short, ok := shortProperty(o.Properties); ok {
+		return short, nil
+	}
+
+	kind := strings.ToLower(o.Class)
+	if kind == "" {
+		kind = strings.ToLower(o.Type)
+	}
+	if kind == "" {
+		kind = strings.ToLower(o.Name)
+	}
+	if kind == tiledSpawnType {
+		return spawnRune, nil
+	}
+	for _, b := range game.AllBlocks {
+		if b.Name == kind {
+			return b.Short, nil
+		}
+	}
+
+	if short, ok := shorts[o.GID&^tiledFlipFlags]; ok {
+		return short, nil
+	}
+	return 0, fmt.Errorf("object %q has no known type", o.Name)
+}
+
+// Finds the "short" property in a list of properties
+func shortProperty(properties []tiledProperty) (rune, bool) {
+	for _, p := range properties {
+		if p.Name == tiledShortProperty {
+			value := []rune(fmt.Sprint(p.Value))
+			if len(value) == 1 {
+				return value[0], true
+			}
+		}
+	}
+	return 0, false
+}
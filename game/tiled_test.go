@@ -0,0 +1,124 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Loads a map of the repository with the default blocks
+func loadTestMap(t *testing.T, path string) Game {
+	t.Helper()
+	config := DefaultConfig()
+	config.MapPath = path
+	g, err := InitGame(config)
+	if err != nil {
+		t.Fatalf("cannot load %s: %v", path, err)
+	}
+	return g
+}
+
+func TestTiledFormatsGiveTheSameMap(t *testing.T) {
+	tmj := loadTestMap(t, "data/maps/tiled/level.tmj")
+	tmx := loadTestMap(t, "data/maps/tiled/level.tmx")
+
+	if !reflect.DeepEqual(tmj.GameMap, tmx.GameMap) {
+		t.Error("level.tmj and level.tmx give different collision layers")
+	}
+	if !reflect.DeepEqual(tmj.Background, tmx.Background) || !reflect.DeepEqual(tmj.Foreground, tmx.Foreground) {
+		t.Error("level.tmj and level.tmx give different decoration layers")
+	}
+
+	for _, g := range []Game{tmj, tmx} {
+		if g.width != 30 || g.height != 10 {
+			t.Errorf("size is %dx%d, expected 30x10", g.width, g.height)
+		}
+		if g.Spawn != (Position{3.5, 5.5}) || g.Player.Position != g.Spawn {
+			t.Errorf("spawn is %v and player is at %v, expected (3.5, 5.5)", g.Spawn, g.Player.Position)
+		}
+		if g.GameMap[0][0] != 's' || g.GameMap[12][4] != 'b' || g.GameMap[22][6] != 'C' || g.GameMap[5][9] != 's' {
+			t.Error("tiles are not placed in their cells")
+		}
+		if g.Background[5][6] != 't' || g.Foreground[4][6] != 'h' {
+			t.Error("decoration tiles are not placed in their layers")
+		}
+	}
+}
+
+func TestTiledObjectsArePlaced(t *testing.T) {
+	for _, path := range []string{"data/maps/tiled/level.tmj", "data/maps/tiled/level.tmx"} {
+		g := loadTestMap(t, path)
+
+		// Coins by type, by "short" property, and the key by its tile
+		for _, cell := range [][2]int{{12, 3}, {14, 3}, {16, 3}, {27, 6}} {
+			if g.GameMap[cell[0]][cell[1]] != 'c' {
+				t.Errorf("%s: no coin at (%d, %d)", path, cell[0], cell[1])
+			}
+		}
+		if g.GameMap[18][6] != 'k' {
+			t.Errorf("%s: no key at (18, 6)", path)
+		}
+	}
+}
+
+// Tiled JSON map of 2x1 tiles of a size (pixels), with a tileset and a layer replacing the defaults
+func tiledTestMap(tileSize int, tileset, layer string) string {
+	if tileset == "" {
+		tileset = `{"firstgid": 1, "tiles": [{"id": 0, "properties": [{"name": "short", "value": "s"}]}]}`
+	}
+	if layer == "" {
+		layer = `{"name": "collision", "type": "tilelayer", "width": 2, "height": 1, "data": [1, 1]}`
+	}
+	return fmt.Sprintf(`{"orientation": "orthogonal", "width": 2, "height": 1, "tilewidth": %d, "tileheight": %d,
+		"tilesets": [%s], "layers": [%s]}`, tileSize, tileSize, tileset, layer)
+}
+
+func TestTiledErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		tileSize int
+		tileset  string
+		layer    string
+		expected string // Part of the error message
+	}{
+		{"valid", 64, "", "", ""},
+		{"no tile size", 0, "", `{"name": "entities", "type": "objectgroup", "objects": [{"name": "start", "type": "spawn"}]}`,
+			"expected a positive size"},
+		{"external tileset", 64, `{"firstgid": 1, "source": "resources.tsj"}`, "", "external tileset"},
+		{"base64 encoding", 64, "",
+			`{"name": "collision", "type": "tilelayer", "width": 2, "height": 1, "encoding": "base64", "data": "AQAAAAEAAAA="}`,
+			"base64 encoding is not supported"},
+		{"tile without short", 64, "",
+			`{"name": "collision", "type": "tilelayer", "width": 2, "height": 1, "data": [1, 2]}`,
+			`tile 2 has no "short" property`},
+	}
+
+	for _, test := range tests {
+		g, err := newGame(DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = g.readTiledJSON(strings.NewReader(tiledTestMap(test.tileSize, test.tileset, test.layer)), false)
+		switch {
+		case test.expected == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		case test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)):
+			t.Errorf("%s: got error %v, expected %q", test.name, err, test.expected)
+		}
+	}
+}
+
+func TestTiledXMLEncoding(t *testing.T) {
+	g, err := newGame(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmx := `<map orientation="orthogonal" width="2" height="1" tilewidth="64" tileheight="64">
+		<layer name="collision" width="2" height="1"><data encoding="base64">AQAAAAEAAAA=</data></layer>
+	</map>`
+	err = g.readTiledXML(strings.NewReader(tmx), false)
+	if err == nil || !strings.Contains(err.Error(), "encoding is not supported") {
+		t.Errorf("got error %v, expected an unsupported encoding", err)
+	}
+}
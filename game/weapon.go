@@ -0,0 +1,129 @@
+package game
+
+import "math"
+
+// WeaponKind enum
+type WeaponKind string
+
+const (
+	Melee  WeaponKind = "Melee"  // Hits in front of the player for a short time
+	Ranged WeaponKind = "Ranged" // Throws a projectile flying straight until it hits something
+)
+
+const swingTime float64 = 0.15                     // Time a melee hit lasts (seconds)
+const entityInvulnerableTime float64 = 0.3         // Time an entity takes no damage after a hit (seconds)
+var projectileBox = AABB{-0.15, -0.15, 0.15, 0.15} // Box around the position of projectiles
+
+// Stats of an item used as a weapon, in the blocks definitions file
+type Weapon struct {
+	Kind     WeaponKind `json:"kind"`
+	Damage   int        `json:"damage"`   // Health removed to the entity hit
+	Range    float64    `json:"range"`    // Reach of a melee hit, or distance flown by a projectile (blocks)
+	Speed    float64    `json:"speed"`    // Speed of projectiles (blocks per second)
+	Cooldown float64    `json:"cooldown"` // Time between two attacks (seconds)
+}
+
+// Melee hit in front of the player
+type Swing struct {
+	Box       AABB
+	Direction rune    // l or r
+	Time      float64 // Time left (seconds), the swing is over at 0
+	Damage    int
+}
+
+// Projectile thrown by the player
+type Projectile struct {
+	Position  Position
+	VelocityX float64 // Horizontal velocity (blocks per second)
+	Distance  float64 // Distance left to fly (blocks)
+	Damage    int
+}
+
+// Returns the box of the projectile at its position
+func (p *Projectile) Box() AABB {
+	return projectileBox.Translate(p.Position.X, p.Position.Y)
+}
+
+// Attacks with the weapon in use when the attack key has just been pressed,
+// and moves the attacks already started
+func (g *Game) stepAttack(input InputState) {
+	pressed := input.Attack && !g.attackHeld
+	g.attackHeld = input.Attack
+
+	if g.Player.AttackCooldown > 0 {
+		g.Player.AttackCooldown -= FixedDelta
+	}
+	if g.Swing.Time > 0 {
+		g.Swing.Time -= FixedDelta
+		g.hitEntities(g.Swing.Box, g.Swing.Damage)
+	}
+	g.stepProjectiles()
+
+	if pressed && g.Player.AttackCooldown <= 0 && g.CountItem(g.Player.Weapon) > 0 {
+		w := g.Items[g.Player.Weapon].Weapon
+		g.Player.AttackCooldown = w.Cooldown
+
+		box := g.Player.Box()
+		direction := 1.0
+		front := AABB{box.MaxX, box.MinY, box.MaxX + w.Range, box.MaxY}
+		if g.Player.Direction == 'l' {
+			direction = -1.0
+			front = AABB{box.MinX - w.Range, box.MinY, box.MinX, box.MaxY}
+		}
+
+		switch w.Kind {
+		case Melee:
+			g.Swing = Swing{front, g.Player.Direction, swingTime, w.Damage}
+			g.hitEntities(front, w.Damage)
+		case Ranged:
+			g.Projectiles = append(g.Projectiles,
+				Projectile{g.Player.Position, direction * w.Speed, w.Range, w.Damage})
+		}
+	}
+
+	g.removeDeadEntities()
+}
+
+// Moves the projectiles, removing those hitting a solid block or an entity,
+// or having flown their whole range
+func (g *Game) stepProjectiles() {
+	kept := []Projectile{}
+	for _, p := range g.Projectiles {
+		dx := p.VelocityX * FixedDelta
+		if _, ok := g.Sweep(p.Box(), dx, 0, false); ok {
+			continue
+		}
+		p.Position.X += dx
+		p.Distance -= math.Abs(dx)
+		if p.Distance <= 0 || g.hitEntities(p.Box(), p.Damage) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	g.Projectiles = kept
+}
+
+// Removes health to the entities in the box (unless just hit),
+// returns true if an entity was hit
+func (g *Game) hitEntities(box AABB, damage int) (hit bool) {
+	for i := range g.Entities {
+		e := &g.Entities[i]
+		if e.Health > 0 && e.Invulnerable <= 0 && overlaps(box, e.Box()) {
+			e.Health -= damage
+			e.Invulnerable = entityInvulnerableTime
+			hit = true
+		}
+	}
+	return
+}
+
+// Removes the entities without health left
+func (g *Game) removeDeadEntities() {
+	alive := []Entity{}
+	for _, e := range g.Entities {
+		if e.Health > 0 {
+			alive = append(alive, e)
+		}
+	}
+	g.Entities = alive
+}
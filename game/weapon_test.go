@@ -0,0 +1,61 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		gameMap   string // Line of the map, walls are blocks and the rest entities
+		weapon    ItemID
+		direction rune
+		presses   int     // Times the attack key is pressed
+		gap       float64 // Time between two presses (seconds)
+		health    int     // Health left to the walker, 0 if removed
+	}{
+		{"no weapon", " pw", "", 'r', 1, 0, 2},
+		{"sword in front", " pw", "sword", 'r', 1, 0, 1},
+		{"sword behind", " pw", "sword", 'l', 1, 0, 2},
+		{"sword out of reach", " p w", "sword", 'r', 1, 0, 2},
+		{"sword during cooldown", " pw", "sword", 'r', 2, 0.2, 1},
+		{"sword after cooldown", " pw", "sword", 'r', 2, 0.4, 0},
+		{"sling", " p      w", "sling", 'r', 1, 0, 1},
+		{"sling out of range", " p         w", "sling", 'r', 1, 0, 2},
+		{"sling against a wall", " p  s   w", "sling", 'r', 1, 0, 2},
+	}
+
+	for _, test := range tests {
+		walls := strings.NewReplacer("p", " ", "w", " ").Replace(test.gameMap)
+		g := newTestGame(t, "[collision]\n"+walls+"\nssssssssssssss\n[entities]\n"+test.gameMap+"\n")
+		for i := range g.Entities {
+			g.Entities[i].VelocityX = 0 // Walkers stand still
+		}
+		if test.weapon != "" {
+			g.AddItem(test.weapon, 1)
+		}
+		g.Player.Direction = test.direction
+
+		for i := 0; i < test.presses; i++ {
+			g.Step(InputState{Attack: true})
+			for s := 0.0; s < test.gap; s += FixedDelta {
+				g.Step(InputState{})
+			}
+		}
+		for s := 0; s < stepsPerSecond; s++ {
+			g.Step(InputState{})
+		}
+
+		health := 0
+		for _, e := range g.Entities {
+			health += e.Health
+		}
+		if health != test.health {
+			t.Errorf("%s: walker has %d health left, expected %d", test.name, health, test.health)
+		}
+		if len(g.Projectiles) != 0 {
+			t.Errorf("%s: %d projectiles still flying", test.name, len(g.Projectiles))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package graphic
+
+import (
+	"fmt"
+	"gopherLand/game"
+	"image"
+	"reflect"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Frames of the atlas drawn by the window itself
+const (
+	heartFullFrame  string = "heart_full"
+	heartEmptyFrame string = "heart_empty"
+	stoneFrame      string = "sling_stone" // Projectiles
+)
+
+var sprites = map[string]*ebiten.Image{} // Frames of the atlas, cut once from the sprite sheet
+var spritesAtlas game.Atlas              // Atlas the frames were cut with
+
+// Loads the sprite sheet of the atlas and cuts all its frames
+// (nothing is done if the same sheet and frames are already loaded)
+func loadSprites(atlas game.Atlas) error {
+	if spritesAtlas.Image == atlas.Image && reflect.DeepEqual(spritesAtlas.Frames, atlas.Frames) {
+		return nil
+	}
+	sheet, _, err := ebitenutil.NewImageFromFile(atlas.Image)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{heartFullFrame, heartEmptyFrame, stoneFrame} {
+		if _, ok := atlas.Frames[name]; !ok {
+			return fmt.Errorf("atlas has no frame %q", name)
+		}
+	}
+
+	sprites = map[string]*ebiten.Image{}
+	for name, f := range atlas.Frames {
+		sprites[name] = sheet.SubImage(image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H)).(*ebiten.Image)
+	}
+	spritesAtlas = atlas
+	return nil
+}
+
+// Returns a frame of the atlas by name
+func sprite(name string) *ebiten.Image {
+	return sprites[name]
+}
@@ -0,0 +1,114 @@
+package graphic
+
+import (
+	"gopherLand/game"
+	"math"
+)
+
+// Size of the zone where the player moves without moving the camera (blocks)
+const cameraDeadZoneWidth float64 = 2.0
+const cameraDeadZoneHeight float64 = 3.0
+
+const cameraSmoothing float64 = 0.15 // Part of the distance to its target the camera moves each frame
+
+// Part of the map shown in the window, positions are in blocks
+type Camera struct {
+	X      float64 // Left of the view
+	Y      float64 // Top of the view
+	Width  float64 // Blocks shown horizontally
+	Height float64 // Blocks shown vertically
+}
+
+// Creates a camera showing the default window, centered on the player
+func newCamera(g *game.Game) Camera {
+	camera := Camera{0, 0, float64(windowWidth) / tileSize, float64(windowHeight) / tileSize}
+	camera.snap(g)
+	return camera
+}
+
+// Changes the blocks shown to fit a view (logical pixels), keeping the view inside the map
+func (cam *Camera) resize(g *game.Game, viewWidth, viewHeight int) {
+	cam.Width = float64(viewWidth) / tileSize
+	cam.Height = float64(viewHeight) / tileSize
+	width, height := mapSize(g)
+	cam.X = clampView(cam.X, cam.Width, width)
+	cam.Y = clampView(cam.Y, cam.Height, height)
+}
+
+// Moves the camera to the player right away (level started, game loaded)
+func (cam *Camera) snap(g *game.Game) {
+	width, height := mapSize(g)
+	cam.X = clampView(g.Player.Position.X-cam.Width/2, cam.Width, width)
+	cam.Y = clampView(g.Player.Position.Y-cam.Height/2, cam.Height, height)
+}
+
+// Moves the camera smoothly toward the player, only once the player leaves the dead zone
+// (it stops at the edges of the map, where the player moves freely across the window)
+func (cam *Camera) follow(g *game.Game) {
+	width, height := mapSize(g)
+	cam.X = followAxis(cam.X, cam.Width, g.Player.Position.X, cameraDeadZoneWidth, width)
+	cam.Y = followAxis(cam.Y, cam.Height, g.Player.Position.Y, cameraDeadZoneHeight, height)
+}
+
+// Returns the new start of the view on an axis, following a target out of the dead zone
+// (it jumps right away if the target is too far, after a respawn for instance)
+func followAxis(start, size, target, deadZone, length float64) float64 {
+	to := start
+	low := start + (size-deadZone)/2
+	high := low + deadZone
+	if target < low {
+		to -= low - target
+	} else if target > high {
+		to += target - high
+	}
+	to = clampView(to, size, length)
+	if math.Abs(to-start) > size/2 {
+		return to
+	}
+	return start + (to-start)*cameraSmoothing
+}
+
+// Keeps the view inside the map on an axis, a map smaller than the window is drawn from its start
+func clampView(start, size, length float64) float64 {
+	if start > length-size {
+		start = length - size
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start
+}
+
+// Returns the size of the map (blocks)
+func mapSize(g *game.Game) (width, height float64) {
+	if len(g.GameMap) == 0 {
+		return 0, 0
+	}
+	return float64(len(g.GameMap)), float64(len(g.GameMap[0]))
+}
+
+// Returns where a position of the map is drawn in the view (logical pixels)
+func (cam *Camera) toScreen(x, y float64) (float64, float64) {
+	return (x - cam.X) * tileSize, (y - cam.Y) * tileSize
+}
+
+// Returns the cells of a layer seen by the camera
+func (cam *Camera) visibleCells(layer [][]rune) (xFrom, xTo, yFrom, yTo int) {
+	xFrom = int(math.Floor(cam.X))
+	xTo = int(math.Floor(cam.X + cam.Width))
+	yFrom = int(math.Floor(cam.Y))
+	yTo = int(math.Floor(cam.Y + cam.Height))
+	if xFrom < 0 {
+		xFrom = 0
+	}
+	if xTo >= len(layer) {
+		xTo = len(layer) - 1
+	}
+	if yFrom < 0 {
+		yFrom = 0
+	}
+	if len(layer) > 0 && yTo >= len(layer[0]) {
+		yTo = len(layer[0]) - 1
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package graphic
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Size of a block in the window (logical pixels), whatever the cell size of the sprite sheet
+const tileSize float64 = 64
+
+// Scaling enum, how logical pixels are scaled to the window
+type Scaling string
+
+const (
+	Integer    Scaling = "integer"    // Each logical pixel is a whole number of pixels, for sharp sprites
+	Fractional Scaling = "fractional" // Logical pixels are scaled to fit the window height exactly
+)
+
+// Settings of the window
+type Display struct {
+	Scaling   Scaling
+	Letterbox bool // Keeps the proportions of the default window, with black bars around
+}
+
+// Returned when the scaling mode is unknown
+type UnknownScalingError struct {
+	Scaling Scaling
+}
+
+func (e *UnknownScalingError) Error() string {
+	return fmt.Sprintf("unknown scaling %q (expected %q or %q)", e.Scaling, Integer, Fractional)
+}
+
+// Default settings, scaling the window freely without black bars
+func DefaultDisplay() Display {
+	return Display{Fractional, false}
+}
+
+// Checks the settings
+func (d Display) Validate() error {
+	if d.Scaling != Integer && d.Scaling != Fractional {
+		return &UnknownScalingError{d.Scaling}
+	}
+	return nil
+}
+
+// Returns the pixels of the window for each logical pixel, so that the view
+// is about as tall as the default window
+func (d Display) renderScale(outsideHeight int) float64 {
+	if outsideHeight <= 0 {
+		return 1
+	}
+	scale := float64(outsideHeight) / float64(windowHeight)
+	if d.Scaling == Integer {
+		if scale >= 1 {
+			return math.Floor(scale)
+		}
+		return 1 / math.Ceil(1/scale)
+	}
+	return scale
+}
+
+// Computes the size of the screen and of the view from the size of the window,
+// the camera shows as many blocks as fit in the view
+func (c *Controller) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	scale := c.display.renderScale(outsideHeight)
+	screenWidth = int(float64(outsideWidth) / scale)
+	screenHeight = int(float64(outsideHeight) / scale)
+
+	c.viewWidth, c.viewHeight = screenWidth, screenHeight
+	if c.display.Letterbox {
+		if c.viewWidth*windowHeight > c.viewHeight*windowWidth {
+			c.viewWidth = c.viewHeight * windowWidth / windowHeight
+		} else {
+			c.viewHeight = c.viewWidth * windowHeight / windowWidth
+		}
+	}
+	c.camera.resize(c.game, c.viewWidth, c.viewHeight)
+	return
+}
+
+// Returns the image the game is drawn on: the screen itself,
+// or an image of the size of the view when letterboxing
+func (c *Controller) viewImage(screen *ebiten.Image) *ebiten.Image {
+	if !c.display.Letterbox {
+		return screen
+	}
+	if c.view == nil || c.view.Bounds().Dx() != c.viewWidth || c.view.Bounds().Dy() != c.viewHeight {
+		if c.view != nil {
+			c.view.Dispose()
+		}
+		c.view = ebiten.NewImage(c.viewWidth, c.viewHeight)
+	}
+	c.view.Clear()
+	return c.view
+}
+
+// Draws the view in the middle of the screen, with black bars around (letterboxing only)
+func (c *Controller) displayLetterbox(screen *ebiten.Image) {
+	if !c.display.Letterbox {
+		return
+	}
+	screen.Fill(color.Black)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(screen.Bounds().Dx()-c.viewWidth)/2, float64(screen.Bounds().Dy()-c.viewHeight)/2)
+	screen.DrawImage(c.view, op)
+}
+
+// Switches between window and fullscreen with F11
+func manageFullscreen() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+}
+
+// Returns the options drawing a frame of the sprite sheet at the scale of the window (a cell
+// of the sheet is a block), mirrored if it faces left, with the bottom center of the frame
+// at the origin: frames of any size stand on the point they are moved to
+func (c *Controller) spriteOptions(frame *ebiten.Image, mirrored bool) *ebiten.DrawImageOptions {
+	scale := tileSize / float64(c.game.BlockSize)
+	width := float64(frame.Bounds().Dx()) * scale
+	height := float64(frame.Bounds().Dy()) * scale
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	if mirrored {
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(width, 0)
+	}
+	op.GeoM.Translate(-width/2, -height)
+	return op
+}
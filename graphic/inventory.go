@@ -0,0 +1,85 @@
+package graphic
+
+import (
+	"image/color"
+	"log"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const iconScale float64 = 0.75 // Item icons are displayed 48 pixels wide
+
+// Manages keys of the inventory: Up and Down choose an item, Enter uses it
+// and I goes back to the game (using items is disabled while recording or replaying)
+func (c *Controller) manageInventory() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyI), inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.inventoryOpen = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		if c.selectedSlot > 0 {
+			c.selectedSlot--
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		if c.selectedSlot < len(c.game.Player.Inventory)-1 {
+			c.selectedSlot++
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if c.recording != nil || c.replay != nil {
+			c.showMessage("Cannot use items while recording or replaying")
+			return
+		}
+		if len(c.game.Player.Inventory) == 0 {
+			return
+		}
+		name := c.game.Items[c.game.Player.Inventory[c.selectedSlot].Item].Name
+		if err := c.game.UseItem(c.selectedSlot); err != nil {
+			log.Printf("Cannot use %s: %s", name, err.Error())
+			c.showMessage(name + " cannot be used now")
+		} else {
+			c.showMessage(name + " used")
+		}
+		if c.selectedSlot >= len(c.game.Player.Inventory) && c.selectedSlot > 0 {
+			c.selectedSlot--
+		}
+	}
+}
+
+// Draw the items of the inventory with their descriptions
+func (c *Controller) displayInventory(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(c.viewWidth), float64(c.viewHeight), color.RGBA{0, 0, 0, 200})
+
+	c.txtRenderer.SetTarget(screen)
+	c.txtRenderer.SetSizePx(42)
+	c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+	c.txtRenderer.Draw("Inventory", 120, 80)
+
+	if len(c.game.Player.Inventory) == 0 {
+		c.txtRenderer.SetColor(color.RGBA{120, 120, 120, 255})
+		c.txtRenderer.Draw("Empty", 160, 160)
+	}
+
+	for i, s := range c.game.Player.Inventory {
+		item := c.game.Items[s.Item]
+		y := 160 + i*64
+
+		icon := sprite(item.Icon)
+		op := c.spriteOptions(icon, false)
+		op.GeoM.Scale(iconScale, iconScale)
+		op.GeoM.Translate(160+tileSize*iconScale/2, float64(y)+tileSize*iconScale)
+		screen.DrawImage(icon, op)
+
+		c.txtRenderer.SetSizePx(42)
+		c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		if i == c.selectedSlot {
+			c.txtRenderer.SetColor(color.RGBA{255, 210, 60, 255})
+		}
+		c.txtRenderer.Draw(item.Name+" x"+strconv.Itoa(s.Count), 230, y)
+
+		c.txtRenderer.SetSizePx(24)
+		c.txtRenderer.SetColor(color.RGBA{200, 200, 200, 255})
+		c.txtRenderer.Draw(item.Description, 560, y+12)
+	}
+}
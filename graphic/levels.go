@@ -0,0 +1,123 @@
+package graphic
+
+import (
+	"gopherLand/game"
+	"image/color"
+	"log"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Updates what depends on the level once another one is played
+func (c *Controller) levelChanged() {
+	backgrounds, err := loadBackgrounds(c.game)
+	if err != nil {
+		log.Printf("Error while loading backgrounds: %s", err.Error())
+		backgrounds = []background{}
+	}
+	c.backgrounds = backgrounds
+	c.camera.snap(c.game)
+	if c.game.Level >= 0 {
+		c.showMessage(c.game.World.Levels[c.game.Level].Name)
+	}
+}
+
+// Unlocks the level after the one completed
+func (c *Controller) unlockNextLevel() {
+	if c.game.Level < 0 || c.game.Level+2 <= c.progress.Unlocked || c.game.Level+1 >= len(c.game.World.Levels) {
+		return
+	}
+	c.progress.Unlocked = c.game.Level + 2
+	if err := c.progress.Save(c.saveDir); err != nil {
+		log.Printf("Error while saving progress: %s", err.Error())
+	}
+}
+
+// Manages keys once the level is complete: Enter goes to the next level,
+// or to the level select screen after the last one
+func (c *Controller) manageLevelEnd() {
+	if c.recording != nil || c.replay != nil {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		if err := c.game.NextLevel(true); err == game.ErrNoNextLevel {
+			c.openLevelSelect()
+		} else if err != nil {
+			log.Printf("Error while loading next level: %s", err.Error())
+			c.showMessage("Cannot load the next level")
+		} else {
+			c.levelChanged()
+		}
+	}
+}
+
+// Shows the level select screen, if another level than the first one is unlocked
+// (disabled while recording or replaying)
+func (c *Controller) openLevelSelect() {
+	if c.recording != nil || c.replay != nil || c.progress.Unlocked < 2 {
+		return
+	}
+	c.levelSelect = true
+	c.selectedLevel = 0
+	if c.game.Level >= 0 && c.game.Level < c.progress.Unlocked {
+		c.selectedLevel = c.game.Level
+	}
+}
+
+// Manages keys of the level select screen: Up and Down choose a level,
+// Enter plays it and Escape goes back to the game
+func (c *Controller) manageLevelSelect() {
+	unlocked := c.progress.Unlocked
+	if unlocked > len(c.game.World.Levels) {
+		unlocked = len(c.game.World.Levels)
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.levelSelect = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		if c.selectedLevel > 0 {
+			c.selectedLevel--
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		if c.selectedLevel < unlocked-1 {
+			c.selectedLevel++
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if err := c.game.LoadLevel(c.selectedLevel, false); err != nil {
+			log.Printf("Error while loading level: %s", err.Error())
+			c.showMessage("Cannot load the level")
+		} else {
+			c.levelChanged()
+		}
+		c.levelSelect = false
+	}
+}
+
+// Draw the list of levels, locked ones in grey
+func (c *Controller) displayLevelSelect(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(c.viewWidth), float64(c.viewHeight), color.RGBA{0, 0, 0, 200})
+
+	c.txtRenderer.SetTarget(screen)
+	c.txtRenderer.SetSizePx(42)
+	c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+	c.txtRenderer.Draw("Choose a level", 120, 80)
+
+	for i, l := range c.game.World.Levels {
+		text := strconv.Itoa(i+1) + ". " + l.Name
+		switch {
+		case i >= c.progress.Unlocked:
+			c.txtRenderer.SetColor(color.RGBA{120, 120, 120, 255})
+			text += " (locked)"
+		case i == c.selectedLevel:
+			c.txtRenderer.SetColor(color.RGBA{255, 210, 60, 255})
+			text = "> " + text
+		default:
+			c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		}
+		c.txtRenderer.Draw(text, 160, 160+i*50)
+	}
+}
@@ -0,0 +1,70 @@
+package graphic
+
+import (
+	"gopherLand/game"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Parallax layers of maps that are not in the world
+var defaultParallax = []game.ParallaxLayer{
+	{Image: "data/images/backgrounds/background.png", Tile: true},
+	{Image: "data/images/backgrounds/background3.png", ScrollX: 0.6, Tile: true},
+}
+
+var backgroundImages = map[string]*ebiten.Image{} // Background images already loaded, by path
+
+// Parallax layer with its image loaded
+type background struct {
+	layer game.ParallaxLayer
+	image *ebiten.Image
+}
+
+// Loads the parallax layers of the level played
+func loadBackgrounds(g *game.Game) ([]background, error) {
+	layers := defaultParallax
+	if g.Level >= 0 {
+		layers = g.World.Levels[g.Level].Parallax
+	}
+	backgrounds := []background{}
+	for _, l := range layers {
+		if _, ok := backgroundImages[l.Image]; !ok {
+			img, _, err := ebitenutil.NewImageFromFile(l.Image)
+			if err != nil {
+				return nil, err
+			}
+			backgroundImages[l.Image] = img
+		}
+		backgrounds = append(backgrounds, background{l, backgroundImages[l.Image]})
+	}
+	return backgrounds, nil
+}
+
+// Draw the parallax layers, from the farthest, each one following the camera by its scroll factors
+func (c *Controller) displayBackgrounds(screen *ebiten.Image) {
+	for _, b := range c.backgrounds {
+		x := -c.camera.X * tileSize * b.layer.ScrollX
+		y := b.layer.OffsetY - c.camera.Y*tileSize*b.layer.ScrollY
+		width := float64(b.image.Bounds().Dx())
+
+		if !b.layer.Tile {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(x, y)
+			screen.DrawImage(b.image, op)
+			continue
+		}
+
+		// Repeating the image from the left of the view to its right
+		x = math.Mod(x, width)
+		if x > 0 {
+			x -= width
+		}
+		for ; x < float64(c.viewWidth); x += width {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(x, y)
+			screen.DrawImage(b.image, op)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package graphic
+
+import (
+	"image/color"
+	"log"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Manages keys of the purchase menu: Up and Down choose an offer, Enter buys it
+// and E goes back to the game (buying is disabled while recording or replaying)
+func (c *Controller) manageShop() {
+	offers := c.game.Shops[c.shop]
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyE), inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.shop = ""
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		if c.selectedOffer > 0 {
+			c.selectedOffer--
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		if c.selectedOffer < len(offers)-1 {
+			c.selectedOffer++
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		if c.recording != nil || c.replay != nil {
+			c.showMessage("Cannot buy while recording or replaying")
+			return
+		}
+		if len(offers) == 0 {
+			return
+		}
+		name := c.game.Items[offers[c.selectedOffer].Item].Name
+		if err := c.game.BuyItem(c.shop, c.selectedOffer); err != nil {
+			log.Printf("Cannot buy %s: %s", name, err.Error())
+			c.showMessage("Cannot buy " + name + ": " + err.Error())
+		} else {
+			c.showMessage(name + " bought")
+		}
+	}
+}
+
+// Draw the offers of the shop with their prices
+func (c *Controller) displayShop(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, float64(c.viewWidth), float64(c.viewHeight), color.RGBA{0, 0, 0, 200})
+
+	c.txtRenderer.SetTarget(screen)
+	c.txtRenderer.SetSizePx(42)
+	c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+	c.txtRenderer.Draw("Shop", 120, 80)
+	c.txtRenderer.SetColor(color.RGBA{188, 94, 16, 255})
+	c.txtRenderer.Draw("Golds: "+strconv.Itoa(c.game.Player.Gold), 760, 80)
+
+	for i, o := range c.game.Shops[c.shop] {
+		item := c.game.Items[o.Item]
+		y := 160 + i*64
+
+		icon := sprite(item.Icon)
+		op := c.spriteOptions(icon, false)
+		op.GeoM.Scale(iconScale, iconScale)
+		op.GeoM.Translate(160+tileSize*iconScale/2, float64(y)+tileSize*iconScale)
+		screen.DrawImage(icon, op)
+
+		switch {
+		case i == c.selectedOffer:
+			c.txtRenderer.SetColor(color.RGBA{255, 210, 60, 255})
+		case o.Price > c.game.Player.Gold:
+			c.txtRenderer.SetColor(color.RGBA{120, 120, 120, 255})
+		default:
+			c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		}
+		c.txtRenderer.Draw(item.Name+" x"+strconv.Itoa(o.Count), 230, y)
+		c.txtRenderer.Draw(strconv.Itoa(o.Price)+" golds", 760, y)
+	}
+}
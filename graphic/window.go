@@ -1,69 +1,141 @@
 package graphic
 
 import (
+	"fmt"
 	"gopherLand/game"
 	"image"
 	"image/color"
 	"log"
+	"math"
 	"strconv"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/tinne26/etxt"
 )
 
+// Default size of the window (logical pixels), the view keeps about this height once scaled
 const windowWidth int = 1280
 const windowHeight int = 720
 
 // var blockDisplayedWidth int
 // var blockDisplayedHeight int
 
-const xPlayerFixed int = 10           // Block shift where the player is
-const framesTillLongJump int = 8      // Frames until long press turns into long jump
-const framesTillCantJumpInAir int = 6 // Frames player can still jump after falling
-
-var framesInAir int = 0 // Counter of frame where player is falling
+const messageFrames int = 120 // Frames a message stays on screen
 
 type Controller struct {
 	game        *game.Game
-	tick        uint64         // Ticks of the game
-	tickFrame   uint8          // Increments each frame, go back to 0 each tick
-	txtRenderer *etxt.Renderer // Used to render text on screen
+	tick        uint64          // Ticks of the game
+	tickFrame   uint8           // Increments each frame, go back to 0 each tick
+	txtRenderer *etxt.Renderer  // Used to render text on screen
+	message     string          // Message displayed on screen (game saved, ...)
+	messageLeft int             // Frames until the message disappears
+	recording   *game.Recording // Input recorded each frame, if recording
+	replay      *game.Recording // Input replayed instead of the keyboard, if replaying
+	replayFrame int             // Next frame of the replay
+	levelStats  game.LevelStats // Player's results, displayed once the level is complete
+
+	// Levels
+	saveDir       string        // Directory where the progress is saved
+	progress      game.Progress // Levels unlocked by the player
+	levelSelect   bool          // True while the level select screen is shown
+	selectedLevel int           // Level chosen in the level select screen
+	backgrounds   []background  // Parallax layers of the level, from the farthest
+
+	// Inventory
+	inventoryOpen bool // True while the inventory is shown
+	selectedSlot  int  // Slot chosen in the inventory
+
+	// Shop
+	shop          string // Shop whose purchase menu is shown, empty if none
+	selectedOffer int    // Offer chosen in the purchase menu
+
+	// Display
+	camera     Camera        // Part of the map shown in the view
+	display    Display       // Settings of the window
+	view       *ebiten.Image // Image the game is drawn on when letterboxing
+	viewWidth  int           // Width of the view (logical pixels)
+	viewHeight int           // Height of the view (logical pixels)
 }
 
-var backgroundImage *ebiten.Image
-var background3Image *ebiten.Image
-var resourcesImage *ebiten.Image
-var iconImage *ebiten.Image
+var slotKeys = []ebiten.Key{ebiten.KeyF1, ebiten.KeyF2, ebiten.KeyF3} // Keys of the save slots 1, 2 and 3
 
-var playerShift float64 // Shift for displaying player
+const heartScale float64 = 0.625 // Hearts are displayed 40 pixels wide
+const stoneScale float64 = 0.5   // Projectiles are displayed 32 pixels wide
+
+var iconImage *ebiten.Image
 
 //////////////////////////////
 // INITIALIZATION FUNCTIONS //
 //////////////////////////////
 
-func initController() Controller {
-	g := game.InitGame(xPlayerFixed)
-	playerShift = 0.5 * float64(g.BlockSize)
+func initController(config game.Config, display Display) (Controller, error) {
+	g, err := game.InitGame(config)
+	if err != nil {
+		return Controller{}, err
+	}
+	if err := loadSprites(g.Atlas); err != nil {
+		return Controller{}, err
+	}
+	for _, w := range g.MapWarnings {
+		log.Printf("Warning: %s, replaced by air", w.Error())
+	}
+	progress, err := game.LoadProgress(config.SaveDir)
+	if err != nil {
+		return Controller{}, err
+	}
+	backgrounds, err := loadBackgrounds(&g)
+	if err != nil {
+		return Controller{}, err
+	}
 	// blockDisplayedWidth = windowWidth/g.BlockSize - 5
 	// blockDisplayedWidth = blockDisplayedHeight/g.BlockSize - 3
-	return Controller{&g, 0, 0, getTxtRenderer()}
+	return Controller{
+		game:        &g,
+		txtRenderer: getTxtRenderer(),
+		saveDir:     config.SaveDir,
+		progress:    progress,
+		backgrounds: backgrounds,
+		camera:      newCamera(&g),
+		display:     display,
+		viewWidth:   windowWidth,
+		viewHeight:  windowHeight,
+	}, nil
 }
 
-func init() {
-	var err error
-	resourcesImage, _, err = ebitenutil.NewImageFromFile("data/images/resources/resources.png")
+// Creates the controller in the state where a recording started
+func initReplayController(config game.Config, display Display, replay *game.Recording) (Controller, error) {
+	g, err := replay.InitGame(config)
 	if err != nil {
-		log.Fatal(err)
+		return Controller{}, err
 	}
-	backgroundImage, _, err = ebitenutil.NewImageFromFile("data/images/backgrounds/background.png")
-	if err != nil {
-		log.Fatal(err)
+	if err := loadSprites(g.Atlas); err != nil {
+		return Controller{}, err
 	}
-	background3Image, _, err = ebitenutil.NewImageFromFile("data/images/backgrounds/background3.png")
+	backgrounds, err := loadBackgrounds(&g)
 	if err != nil {
-		log.Fatal(err)
+		return Controller{}, err
 	}
+	return Controller{
+		game:        &g,
+		tick:        replay.Tick,
+		tickFrame:   replay.TickFrame,
+		txtRenderer: getTxtRenderer(),
+		message:     "Replaying",
+		messageLeft: messageFrames,
+		replay:      replay,
+		saveDir:     config.SaveDir,
+		backgrounds: backgrounds,
+		camera:      newCamera(&g),
+		display:     display,
+		viewWidth:   windowWidth,
+		viewHeight:  windowHeight,
+	}, nil
+}
+
+func init() {
+	var err error
 	iconImage, _, err = ebitenutil.NewImageFromFile("data/images/icons/icon.png")
 	if err != nil {
 		log.Fatal(err)
@@ -78,12 +150,57 @@ func init() {
 func (c *Controller) Update() error {
 	// Tick management (each 12 frames = 200 ms)
 	c.manageTick()
+	manageFullscreen()
 
-	// Manages jumping
-	c.manageJumpOrFall()
+	if c.messageLeft > 0 {
+		c.messageLeft--
+	}
 
-	// Manages button clicks
-	c.manageButtonClicks()
+	// Level select screen and inventory pause the game
+	if c.levelSelect {
+		c.manageLevelSelect()
+		return nil
+	}
+	if c.inventoryOpen {
+		c.manageInventory()
+		return nil
+	}
+	if c.shop != "" {
+		c.manageShop()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		c.inventoryOpen = true
+		c.selectedSlot = 0
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.openLevelSelect()
+	}
+	if c.game.Finished {
+		c.manageLevelEnd()
+	}
+
+	// Saves and loads
+	c.manageSaves()
+
+	// Advances the game with the keyboard state, or the replayed input
+	lives := c.game.Player.Lives
+	if c.replay != nil {
+		c.stepReplay()
+	} else {
+		input := readInput()
+		c.game.Update(input, 1/float64(ebiten.TPS()))
+		if c.recording != nil {
+			c.recording.Add(input)
+		}
+	}
+
+	if c.game.Player.Lives > lives {
+		c.showMessage("Game over, the level starts again")
+	}
+	c.manageEvents()
+	c.camera.follow(c.game)
 
 	return nil
 }
@@ -98,68 +215,137 @@ func (c *Controller) manageTick() {
 	}
 }
 
-// Manages jump and fall of player
-func (c *Controller) manageJumpOrFall() {
-	// c.game.Player.TouchingGround = false
-	// Forces the player to stop walking while on the air
-	/*
-		if c.game.Player.Walking {
-			c.game.Player.Walking = false
-		}
-	*/
-	// Move vertically the player depending on its vertical velocity
-	c.game.Player.VerticalVelocity += 1.0
-	if c.game.Move(0.0, (0.01 * c.game.Player.VerticalVelocity)) {
-		if framesInAir == framesTillCantJumpInAir {
-			framesInAir = 0
-			c.game.Player.TouchingGround = false
+// Advances the game with the next frame of the replay, and checks its end
+func (c *Controller) stepReplay() {
+	if c.replayFrame >= c.replay.Len() {
+		return
+	}
+
+	c.game.Update(c.replay.Input(c.replayFrame), c.replay.Delta)
+	c.replayFrame++
+
+	if c.replayFrame == c.replay.Len() {
+		if err := c.replay.Check(c.game); err != nil {
+			log.Printf("Replay mismatch: %s", err.Error())
+			c.showMessage("Replay mismatch")
 		} else {
-			framesInAir++
+			log.Printf("Replay matches the recording (%d frames)", c.replay.Len())
+			c.showMessage("Replay matches the recording")
 		}
 	}
 }
 
-// Manages input for controlling player
-func (c *Controller) manageButtonClicks() {
-	var notClickedLeft, notClickedRight bool
-
-	// Left button clicked
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-		c.game.Player.Direction = 'l'
-		c.game.Player.Walking = c.game.Move(-c.game.Player.Speed, 0)
-	} else {
-		notClickedLeft = true
+// Reacts to what happened in the game during the frame
+func (c *Controller) manageEvents() {
+	for _, e := range c.game.PollEvents() {
+		switch e.Kind {
+		case game.CheckpointReached:
+			c.showMessage("Checkpoint reached")
+		case game.LevelComplete:
+			c.levelStats = e.Stats
+			if c.replay == nil {
+				c.unlockNextLevel()
+			}
+		case game.TimeUp:
+			c.showMessage("Time is up, the level starts again")
+		case game.ChestOpened:
+			c.showMessage("Found " + c.lootName(e.Loot))
+		case game.InventoryFull:
+			c.showMessage("Inventory full, cannot take " + c.lootName(e.Loot))
+		case game.ShopOpened:
+			if c.replay == nil {
+				c.shop = e.Shop
+				c.selectedOffer = 0
+			}
+		}
 	}
+}
 
-	// Right button clicked
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-		c.game.Player.Direction = 'r'
-		c.game.Player.Walking = c.game.Move(c.game.Player.Speed, 0)
-	} else {
-		notClickedRight = true
+// Describes the content of a chest
+func (c *Controller) lootName(loot game.LootEntry) string {
+	if loot.Item == "" {
+		return strconv.Itoa(loot.Gold) + " golds"
 	}
-
-	if notClickedLeft && notClickedRight && c.game.Player.Walking {
-		c.game.Player.Walking = false
+	name := c.game.Items[loot.Item].Name
+	if loot.Count > 1 {
+		name += " x" + strconv.Itoa(loot.Count)
 	}
+	return name
+}
 
-	// Up button clicked
-	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
-		if c.game.Player.TouchingGround {
-			c.game.Jump = 1
-			c.game.Player.TouchingGround = false
-			c.game.Player.VerticalVelocity = c.game.Player.VelocityShortJump
-			c.game.Move(0.0, -0.01)
-		} else {
-			if c.game.Jump > 0 && c.game.Jump < framesTillLongJump {
-				c.game.Jump++
-			} else if c.game.Jump == framesTillLongJump {
-				c.game.Jump++
-				c.game.Player.VerticalVelocity += c.game.Player.VelocityDiffLongJump
+// Manages save keys: F5 quick saves, F9 quick loads,
+// F1 to F3 load a slot and Shift + F1 to F3 save in a slot
+// (loading is disabled while recording or replaying)
+func (c *Controller) manageSaves() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		c.save(game.QuickSaveSlot)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		c.load(game.QuickSaveSlot)
+	}
+	for i, key := range slotKeys {
+		slot := i + 1
+		if inpututil.IsKeyJustPressed(key) {
+			if ebiten.IsKeyPressed(ebiten.KeyShift) {
+				c.save(slot)
+			} else {
+				c.load(slot)
 			}
 		}
+	}
+}
+
+// Saves the game in a slot and tells it on screen
+func (c *Controller) save(slot int) {
+	if err := c.game.Save(slot); err != nil {
+		log.Printf("Error while saving: %s", err.Error())
+		c.showMessage("Cannot save the game")
+	} else if slot == game.QuickSaveSlot {
+		c.showMessage("Quick saved")
 	} else {
-		c.game.Jump = 0
+		c.showMessage("Saved in slot " + strconv.Itoa(slot))
+	}
+}
+
+// Loads the game from a slot and tells it on screen
+func (c *Controller) load(slot int) {
+	if c.recording != nil || c.replay != nil {
+		c.showMessage("Cannot load while recording or replaying")
+		return
+	}
+	level := c.game.Level
+	if err := c.game.Load(slot); err != nil {
+		log.Printf("Error while loading: %s", err.Error())
+		c.showMessage("Cannot load: " + err.Error())
+		return
+	}
+	if c.game.Level != level {
+		c.levelChanged()
+	}
+	if slot == game.QuickSaveSlot {
+		c.showMessage("Quick loaded")
+	} else {
+		c.showMessage("Loaded slot " + strconv.Itoa(slot))
+	}
+}
+
+// Displays a message on screen for a while
+func (c *Controller) showMessage(message string) {
+	c.message = message
+	c.messageLeft = messageFrames
+}
+
+// Turns the keyboard state into the game's input
+func readInput() game.InputState {
+	return game.InputState{
+		Left:     ebiten.IsKeyPressed(ebiten.KeyArrowLeft),
+		Right:    ebiten.IsKeyPressed(ebiten.KeyArrowRight),
+		Jump:     ebiten.IsKeyPressed(ebiten.KeyArrowUp),
+		Down:     ebiten.IsKeyPressed(ebiten.KeyArrowDown),
+		Interact: ebiten.IsKeyPressed(ebiten.KeyE),
+		Attack:   ebiten.IsKeyPressed(ebiten.KeySpace),
+		Mine:     ebiten.IsKeyPressed(ebiten.KeyZ),
+		Place:    ebiten.IsKeyPressed(ebiten.KeyX),
 	}
 }
 
@@ -167,83 +353,123 @@ func (c *Controller) manageButtonClicks() {
 // DRAWING ON WINDOW //
 ///////////////////////
 
-func (c *Controller) Draw(screen *ebiten.Image) {
+func (c *Controller) Draw(window *ebiten.Image) {
+	screen := c.viewImage(window)
 	c.displayBackgrounds(screen)
-	c.displayBlocks(screen)
+	c.displayBlocks(screen, c.game.Background)
+	c.displayBlocks(screen, c.game.GameMap)
+	c.displayMining(screen)
+	c.displayEntities(screen)
+	c.displayAttacks(screen)
 	c.displayPlayer(screen)
+	c.displayBlocks(screen, c.game.Foreground)
+	c.displayHUD(screen)
+	if c.levelSelect {
+		c.displayLevelSelect(screen)
+	}
+	if c.inventoryOpen {
+		c.displayInventory(screen)
+	}
+	if c.shop != "" {
+		c.displayShop(screen)
+	}
+	c.displayMessage(screen)
+	c.displayLetterbox(window)
 }
 
-// Draw backgrounds
-func (c *Controller) displayBackgrounds(screen *ebiten.Image) {
-	// Fixed background image
-	screen.DrawImage(backgroundImage, nil)
-
-	// Moving background image
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(-c.game.Player.Position.X*0.6*float64(c.game.BlockSize), 0)
-	screen.DrawImage(background3Image, op)
-}
-
-// Draw all blocks of the map
-func (c *Controller) displayBlocks(screen *ebiten.Image) {
+// Draw all blocks of a layer of the map
+func (c *Controller) displayBlocks(screen *ebiten.Image, layer [][]rune) {
 
-	xFrom := int(c.game.Player.Position.X) - xPlayerFixed
-	if xFrom < 0 {
-		xFrom = 0
-	}
-	xTo := int(c.game.Player.Position.X) + xPlayerFixed
-	if xTo >= len(c.game.GameMap) {
-		xTo = len(c.game.GameMap) - 1
-	}
+	xFrom, xTo, yFrom, yTo := c.camera.visibleCells(layer)
 	for x := xFrom; x <= xTo; x++ {
-		for y := 0; y < len(c.game.GameMap[x]); y++ {
+		for y := yFrom; y <= yTo; y++ {
 
-			block := c.game.AllBlocks[c.game.GameMap[x][y]]
+			block := c.game.AllBlocks[layer[x][y]]
 
 			modulo := c.getModulo(block)
 
-			if len(block.Images) > 0 {
-				op := &ebiten.DrawImageOptions{}
-				op.GeoM.Translate(float64(c.game.BlockSize*x)-
-					c.game.Player.Position.X*float64(c.game.BlockSize)+
-					float64(xPlayerFixed*c.game.BlockSize),
-					float64(c.game.BlockSize*y))
-				screen.DrawImage(resourcesImage.SubImage(
-					image.Rect(block.Images[modulo].X1, block.Images[modulo].Y1,
-						block.Images[modulo].X2, block.Images[modulo].Y2)).(*ebiten.Image), op)
+			if len(block.Frames) > 0 {
+				frame := sprite(block.Frames[modulo])
+				op := c.spriteOptions(frame, false)
+				op.GeoM.Translate(c.camera.toScreen(float64(x)+0.5, float64(y)+1)) // Bottom center of the cell
+				screen.DrawImage(frame, op)
 			}
 		}
 	}
 }
 
+// Draw the entities moving in the map
+func (c *Controller) displayEntities(screen *ebiten.Image) {
+	for _, e := range c.game.Entities {
+		block := c.game.AllBlocks[e.Short]
+		if len(block.Frames) == 0 {
+			continue
+		}
+
+		frame := sprite(block.Frames[c.getModulo(block)])
+		op := c.spriteOptions(frame, e.Direction == 'l')
+		op.GeoM.Translate(c.camera.toScreen(e.Position.X, e.Box().MaxY)) // Bottom center of the hitbox
+		screen.DrawImage(frame, op)
+	}
+}
+
+// Draw the progress of the block being mined, as a bar over it
+func (c *Controller) displayMining(screen *ebiten.Image) {
+	mining := c.game.Mining
+	hardness := c.game.AllBlocks[c.game.GameMap[mining.X][mining.Y]].Hardness
+	if mining.Time <= 0 || hardness <= 0 {
+		return
+	}
+	x, y := c.camera.toScreen(float64(mining.X), float64(mining.Y))
+	ebitenutil.DrawRect(screen, x+4, y+4, tileSize-8, 8, color.RGBA{0, 0, 0, 160})
+	ebitenutil.DrawRect(screen, x+4, y+4, (tileSize-8)*math.Min(mining.Time/hardness, 1), 8,
+		color.RGBA{255, 255, 255, 220})
+}
+
+// Draw the melee hit and the projectiles of the player
+func (c *Controller) displayAttacks(screen *ebiten.Image) {
+	if item, ok := c.game.Items[c.game.Player.Weapon]; ok && c.game.Swing.Time > 0 {
+		icon := sprite(item.Icon)
+		op := c.spriteOptions(icon, c.game.Swing.Direction == 'l')
+		op.GeoM.Scale(iconScale, iconScale)
+		centerX := (c.game.Swing.Box.MinX + c.game.Swing.Box.MaxX) / 2
+		centerY := (c.game.Swing.Box.MinY + c.game.Swing.Box.MaxY) / 2
+		op.GeoM.Translate(c.camera.toScreen(centerX, centerY))
+		op.GeoM.Translate(0, tileSize*iconScale/2) // Icons of a cell are centered on the hit
+		screen.DrawImage(icon, op)
+	}
+
+	for _, p := range c.game.Projectiles {
+		stone := sprite(stoneFrame)
+		op := c.spriteOptions(stone, false)
+		op.GeoM.Scale(stoneScale, stoneScale)
+		op.GeoM.Translate(c.camera.toScreen(p.Position.X, p.Position.Y))
+		op.GeoM.Translate(0, tileSize*stoneScale/2)
+		screen.DrawImage(stone, op)
+	}
+}
+
 // Draw the player
 func (c *Controller) displayPlayer(screen *ebiten.Image) {
-	modulo := c.getModulo(c.game.AllBlocks['p'])
-
-	op := &ebiten.DrawImageOptions{}
-	if c.game.Player.Direction == 'l' {
-		// Mirroring image
-		op.GeoM.Scale(-1, 1)
-		op.GeoM.Translate(float64(c.game.BlockSize)-playerShift, -playerShift)
-	} else {
-		op.GeoM.Translate(-playerShift, -playerShift)
+	// Blinking while invulnerable
+	if c.game.Player.Invulnerable > 0 && c.tick%2 == 1 {
+		return
 	}
-	op.GeoM.Translate(float64(xPlayerFixed*c.game.BlockSize),
-		c.game.Player.Position.Y*float64(c.game.BlockSize))
+
+	player := c.game.AllBlocks['p']
+	frame := player.Frames[1]
 	if c.game.Player.Walking {
-		screen.DrawImage(resourcesImage.SubImage(
-			image.Rect(c.game.AllBlocks['p'].Images[modulo].X1,
-				c.game.AllBlocks['p'].Images[modulo].Y1,
-				c.game.AllBlocks['p'].Images[modulo].X2,
-				c.game.AllBlocks['p'].Images[modulo].Y2)).(*ebiten.Image), op)
-	} else {
-		screen.DrawImage(resourcesImage.SubImage(
-			image.Rect(c.game.AllBlocks['p'].Images[1].X1,
-				c.game.AllBlocks['p'].Images[1].Y1,
-				c.game.AllBlocks['p'].Images[1].X2,
-				c.game.AllBlocks['p'].Images[1].Y2)).(*ebiten.Image), op)
+		frame = player.Frames[c.getModulo(player)]
 	}
 
+	frameImage := sprite(frame)
+	op := c.spriteOptions(frameImage, c.game.Player.Direction == 'l')
+	op.GeoM.Translate(c.camera.toScreen(c.game.Player.Position.X, c.game.Player.Box().MaxY)) // Bottom center of the hitbox
+	screen.DrawImage(frameImage, op)
+}
+
+// Draw the player's stats
+func (c *Controller) displayHUD(screen *ebiten.Image) {
 	// Config textRenderer
 	c.txtRenderer.SetTarget(screen)
 	c.txtRenderer.SetSizePx(42)
@@ -252,9 +478,68 @@ func (c *Controller) displayPlayer(screen *ebiten.Image) {
 	c.txtRenderer.SetColor(color.RGBA{188, 94, 16, 255})
 	c.txtRenderer.Draw("Golds: "+strconv.Itoa(c.game.Player.Gold), 20, 10)
 
-	// Display number of golds
+	// Display number of keys
 	c.txtRenderer.SetColor(color.RGBA{147, 31, 124, 255})
-	c.txtRenderer.Draw("Keys: "+strconv.Itoa(c.game.Player.Keys), 20, 40)
+	c.txtRenderer.Draw("Keys: "+strconv.Itoa(c.game.CountItem(game.KeyItem)), 20, 40)
+
+	// Display the weapon in use
+	if item, ok := c.game.Items[c.game.Player.Weapon]; ok {
+		c.txtRenderer.SetColor(color.RGBA{200, 210, 225, 255})
+		c.txtRenderer.Draw("Weapon: "+item.Name, 20, 70)
+	}
+
+	// Display the block held
+	if item, ok := c.game.Items[c.game.Player.Block]; ok {
+		c.txtRenderer.SetColor(color.RGBA{160, 110, 60, 255})
+		c.txtRenderer.Draw(fmt.Sprintf("Block: %s x%d", item.Name, c.game.CountItem(item.ID)), 20, 100)
+	}
+
+	// Display hearts and lives
+	for i := 0; i < c.game.Player.MaxHealth; i++ {
+		heart := heartEmptyFrame
+		if i < c.game.Player.Health {
+			heart = heartFullFrame
+		}
+		icon := sprite(heart)
+		op := c.spriteOptions(icon, false)
+		op.GeoM.Scale(heartScale, heartScale)
+		op.GeoM.Translate(float64(260+i*44)+tileSize*heartScale/2, 14+tileSize*heartScale)
+		screen.DrawImage(icon, op)
+	}
+	c.txtRenderer.SetColor(color.RGBA{220, 40, 60, 255})
+	c.txtRenderer.Draw("x"+strconv.Itoa(c.game.Player.Lives), 264+c.game.Player.MaxHealth*44, 10)
+
+	// Display the time left
+	if c.game.TimeLimit > 0 {
+		left := int(math.Ceil(c.game.TimeLimit - c.game.Time))
+		c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		c.txtRenderer.Draw("Time: "+strconv.Itoa(left), c.viewWidth-200, 10)
+	}
+
+	// Display the player's results once the level is complete
+	if c.game.Finished {
+		minutes := int(c.levelStats.Time) / 60
+		seconds := c.levelStats.Time - float64(minutes*60)
+		c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		c.txtRenderer.Draw("Level complete!", c.viewWidth/2-150, c.viewHeight/2-100)
+		c.txtRenderer.Draw(fmt.Sprintf("Time: %d:%04.1f", minutes, seconds), c.viewWidth/2-150, c.viewHeight/2-50)
+		c.txtRenderer.Draw(fmt.Sprintf("Coins: %d / %d", c.levelStats.Coins, c.levelStats.TotalCoins),
+			c.viewWidth/2-150, c.viewHeight/2)
+		c.txtRenderer.Draw("Keys: "+strconv.Itoa(c.levelStats.Keys), c.viewWidth/2-150, c.viewHeight/2+50)
+		if c.recording == nil && c.replay == nil {
+			c.txtRenderer.Draw("Press Enter to continue", c.viewWidth/2-150, c.viewHeight/2+120)
+		}
+	}
+}
+
+// Draw the message, over the screens
+func (c *Controller) displayMessage(screen *ebiten.Image) {
+	if c.messageLeft > 0 {
+		c.txtRenderer.SetTarget(screen)
+		c.txtRenderer.SetSizePx(42)
+		c.txtRenderer.SetColor(color.RGBA{255, 255, 255, 255})
+		c.txtRenderer.Draw(c.message, 20, c.viewHeight-50)
+	}
 }
 
 /////////////////////
@@ -263,28 +548,49 @@ func (c *Controller) displayPlayer(screen *ebiten.Image) {
 
 // Used to make animation of blocks
 func (c Controller) getModulo(block game.Block) int {
-	max := len(block.Images)
+	max := len(block.Frames)
 	modulo := 0
-	if max > 1 {
-		modulo = int(c.tick) % max
+	if max > 1 && block.TicksPerFrame > 0 {
+		modulo = int(c.tick) / block.TicksPerFrame % max
 	}
 	return modulo
 }
 
-func (c *Controller) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return windowWidth, windowHeight
-}
-
-func OpenWindow() {
+// Opens the game window. If recordPath is set, the input of each frame is
+// recorded in this file when the window is closed. If replay is set, its input
+// is played instead of the keyboard.
+func OpenWindow(config game.Config, display Display, recordPath string, replay *game.Recording) {
 	ebiten.SetWindowSize(windowWidth, windowHeight)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("GopherLand")
 	ebiten.SetWindowIcon([]image.Image{iconImage})
 
-	controler := initController()
+	var controler Controller
+	var err error
+	if replay != nil {
+		controler, err = initReplayController(config, display, replay)
+	} else {
+		controler, err = initController(config, display)
+	}
+	if err != nil {
+		log.Fatalf("Error while loading game: %s", err.Error())
+	}
+
+	if recordPath != "" {
+		controler.recording = controler.game.StartRecording(controler.tick, controler.tickFrame,
+			1/float64(ebiten.TPS()))
+	}
 
-	err := ebiten.RunGame(&controler)
+	err = ebiten.RunGame(&controler)
 
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if controler.recording != nil {
+		controler.recording.Finish(controler.game)
+		if err := controler.recording.Save(recordPath); err != nil {
+			log.Fatalf("Error while saving recording: %s", err.Error())
+		}
+	}
 }
@@ -1,7 +1,43 @@
 package main
 
-import "gopherLand/graphic"
+import (
+	"flag"
+	"gopherLand/game"
+	"gopherLand/graphic"
+	"log"
+)
 
 func main() {
-	graphic.OpenWindow()
+	config := game.DefaultConfig()
+	flag.StringVar(&config.MapPath, "map", config.MapPath, "Path of the map file to play")
+	flag.StringVar(&config.LevelsPath, "levels", config.LevelsPath, "Path of the levels manifest")
+	flag.StringVar(&config.BlocksPath, "blocks", config.BlocksPath, "Path of the blocks definitions file")
+	flag.StringVar(&config.AtlasPath, "atlas", config.AtlasPath, "Path of the atlas manifest of the sprite sheet")
+	flag.BoolVar(&config.Strict, "strict", config.Strict, "Refuse to start if the map contains unknown blocks")
+	recordPath := flag.String("record", "", "Record the input of each frame in this file")
+	replayPath := flag.String("replay", "", "Replay the input recorded in this file")
+	display := graphic.DefaultDisplay()
+	scaling := flag.String("scaling", string(display.Scaling), "Scaling of the window, integer or fractional")
+	flag.BoolVar(&display.Letterbox, "letterbox", display.Letterbox, "Keep the proportions of the view with black bars")
+	flag.Parse()
+
+	display.Scaling = graphic.Scaling(*scaling)
+	if err := display.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *recordPath != "" && *replayPath != "" {
+		log.Fatal("cannot record (-record) while replaying (-replay)")
+	}
+
+	var replay *game.Recording
+	if *replayPath != "" {
+		var err error
+		replay, err = game.LoadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	graphic.OpenWindow(config, display, *recordPath, replay)
 }